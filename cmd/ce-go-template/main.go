@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alitari/ce-go-template/pkg/app"
+)
+
+// ModeEnv selects the mode when the binary is started without arguments, e.g. in a container
+const ModeEnv = "CE_GO_TEMPLATE_MODE"
+
+var usage = `ce-go-template runs one of the ce-go-template modes or develops and tests templates offline
+
+Usage:
+  ce-go-template <mode> [flags]
+  ce-go-template <command> [flags]
+
+Modes:
+  ` + strings.Join(app.Names(), "\n  ") + `
+
+Commands:
+  render    renders a template against a cloud event
+  test      runs the golden file test cases of templates
+
+Without arguments the mode is taken from the ` + ModeEnv + ` environment variable.
+Use "ce-go-template <mode|command> -h" for the flags of a mode or command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		if mode := os.Getenv(ModeEnv); mode != "" {
+			return app.Main(mode, nil)
+		}
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if app.IsMode(args[0]) {
+		return app.Main(args[0], args[1:])
+	}
+	switch args[0] {
+	case "render":
+		return renderCommand(args[1:], stdin, stdout, stderr)
+	case "test":
+		return testCommand(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command '%s'\n\n%s", args[0], usage)
+		return 2
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/render"
+)
+
+// stringList flag which can be repeated
+type stringList []string
+
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ",")
+}
+
+func (sl *stringList) Set(value string) error {
+	*sl = append(*sl, value)
+	return nil
+}
+
+// headerMap the headers 'Name:value' by name
+func headerMap(headers []string) map[string]string {
+	result := map[string]string{}
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) == 2 {
+			result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return result
+}
+
+func renderCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	mode := flags.String("mode", string(render.Mapper), "one of mapper, filter, http-client-mapper, http-client-filter")
+	template := flags.String("template", "", "CE_TEMPLATE in mapper and filter mode, RESPONSE_TEMPLATE in http modes")
+	templateFile := flags.String("template-file", "", "file with the template, overrides -template")
+	eventFile := flags.String("event", "-", "file with the cloud event in structured mode, '-' reads stdin. Json without specversion is the event data")
+	source := flags.String("source", "", "CE_SOURCE of the outgoing event in mapper mode")
+	ceType := flags.String("type", "", "CE_TYPE of the outgoing event in mapper mode")
+	requestTemplate := flags.String("request-template", "", "REQUEST_TEMPLATE in http modes")
+	requestTemplateFile := flags.String("request-template-file", "", "file with the request template, overrides -request-template")
+	stepsFile := flags.String("steps-file", "", "file with REQUEST_STEPS in http modes")
+	jsonBody := flags.Bool("json-body", true, "HTTP_JSON_BODY in http modes")
+	graphQLURL := flags.String("graphql-url", "", "GRAPHQL_URL in http modes, the query is sent instead of the request template")
+	graphQLQueryFile := flags.String("graphql-query-file", "", "GRAPHQL_QUERY_FILE in http modes")
+	graphQLVariablesTemplate := flags.String("graphql-variables-template", "", "GRAPHQL_VARIABLES_TEMPLATE in http modes")
+	graphQLFailOnErrors := flags.Bool("graphql-fail-on-errors", true, "GRAPHQL_FAIL_ON_ERRORS in http modes")
+	graphQLHeaders := stringList{}
+	flags.Var(&graphQLHeaders, "graphql-header", "additional GraphQL request header 'Name:value', can be repeated")
+	mockResponses := stringList{}
+	flags.Var(&mockResponses, "mock-response", "file with a raw http response or a response body, can be repeated for several requests")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	options, err := renderOptions(render.Mode(*mode), *template, *templateFile, *requestTemplate, *requestTemplateFile, *stepsFile, mockResponses)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	options.Source, options.Type, options.JSONBody = *source, *ceType, *jsonBody
+	if options.GraphQL, err = render.NewGraphQL(*graphQLURL, *graphQLQueryFile, *graphQLVariablesTemplate, headerMap(graphQLHeaders), *graphQLFailOnErrors); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	var content []byte
+	if *eventFile == "-" {
+		content, err = ioutil.ReadAll(stdin)
+	} else {
+		content, err = ioutil.ReadFile(*eventFile)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	event, err := render.ReadEvent(content)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	result, err := render.Render(options, event)
+	if result != nil {
+		for i, request := range result.Requests {
+			fmt.Fprintf(stderr, "--- request %d ---\n%s\n", i+1, request)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "render error: %v\n", err)
+		return 1
+	}
+	output, err := result.Output(options.Mode)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintln(stdout, string(output))
+	return 0
+}
+
+func renderOptions(mode render.Mode, template, templateFile, requestTemplate, requestTemplateFile, stepsFile string, mockResponseFiles []string) (render.Options, error) {
+	options := render.Options{Mode: mode}
+	var err error
+	if options.Template, err = render.ValueOrFile(template, templateFile); err != nil {
+		return options, err
+	}
+	if options.RequestTemplate, err = render.ValueOrFile(requestTemplate, requestTemplateFile); err != nil {
+		return options, err
+	}
+	if stepsFile != "" {
+		stepsJSON, err := ioutil.ReadFile(stepsFile)
+		if err != nil {
+			return options, err
+		}
+		if options.Steps, err = cehttpclienttransformer.ParseSteps(string(stepsJSON)); err != nil {
+			return options, err
+		}
+	}
+	for _, fileName := range mockResponseFiles {
+		response, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return options, err
+		}
+		options.MockResponses = append(options.MockResponses, string(response))
+	}
+	return options, nil
+}
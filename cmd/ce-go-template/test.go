@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/alitari/ce-go-template/pkg/templatetest"
+)
+
+func testCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	update := flags.Bool("update", false, "write the expected files with the actual output instead of comparing")
+	verbose := flags.Bool("v", false, "list passed test cases too")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ce-go-template test [flags] [dir]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	root := "templates"
+	if flags.NArg() > 0 {
+		root = flags.Arg(0)
+	}
+
+	results, err := templatetest.Run(root, *update)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	failed := 0
+	for _, result := range results {
+		name := result.Template + "/" + result.Case
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(stdout, "FAIL %s\n     error: %v\n", name, result.Err)
+		case len(result.Diffs) > 0:
+			failed++
+			fmt.Fprintf(stdout, "FAIL %s\n", name)
+			for _, diff := range result.Diffs {
+				fmt.Fprintf(stdout, "     %s\n", diff)
+			}
+		case result.Updated:
+			fmt.Fprintf(stdout, "UPD  %s\n", name)
+		case *verbose:
+			fmt.Fprintf(stdout, "ok   %s\n", name)
+		}
+	}
+	fmt.Fprintf(stdout, "%d test cases, %d failed\n", len(results), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/alitari/ce-go-template/pkg/app"
+)
+
+func main() {
+	os.Exit(app.Main("pipeline", os.Args[1:]))
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/config"
+	"github.com/alitari/ce-go-template/pkg/logging"
+)
+
+// Mode a mode of the ce-go-template binary. The implementation is the envconfig spec of the mode.
+type Mode interface {
+	// Info the configuration for the log
+	Info() string
+	// Validate parses all templates and files without starting the mode
+	Validate() error
+	// Run starts the mode and returns when it is finished or the context of the runtime is done
+	Run(rt *Runtime) error
+	// runtimeConfig the configuration of admin server and shutdown, provided by embedding RuntimeConfig
+	runtimeConfig() *RuntimeConfig
+}
+
+// exit codes of Main
+const (
+	exitOK              = 0
+	exitError           = 1
+	exitUsage           = 2
+	exitShutdownTimeout = 3
+)
+
+var modes = map[string]func() Mode{
+	"periodic-producer":    func() Mode { return &PeriodicProducerConfig{} },
+	"http-server-producer": func() Mode { return &HTTPServerProducerConfig{} },
+	"file-producer":        func() Mode { return &FileProducerConfig{} },
+	"mapper":               func() Mode { return &MapperConfig{} },
+	"filter":               func() Mode { return &FilterConfig{} },
+	"http-client-mapper":   func() Mode { return &HTTPClientMapperConfig{} },
+	"http-client-filter":   func() Mode { return &HTTPClientFilterConfig{} },
+	"grpc-client-mapper":   func() Mode { return &GRPCClientMapperConfig{} },
+	"recorder":             func() Mode { return &RecorderConfig{} },
+	"pipeline":             func() Mode { return &PipelineConfig{} },
+}
+
+// Names of all modes
+func Names() []string {
+	names := []string{}
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsMode true if a mode with this name exists
+func IsMode(name string) bool {
+	_, ok := modes[name]
+	return ok
+}
+
+// Main loads the configuration of the mode from config file, environment and flags and runs it. The result is the exit code.
+func Main(name string, args []string) int {
+	return run(name, args, os.Stdout, os.Stderr)
+}
+
+func run(name string, args []string, stdout, stderr io.Writer) int {
+	newMode, ok := modes[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown mode '%s'\n", name)
+		return exitUsage
+	}
+	mode := newMode()
+	options, err := config.Load(name, mode, args, stderr)
+	if err == flag.ErrHelp {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if options.PrintConfig {
+		if err := config.Print(stdout, mode); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+	if options.Validate {
+		if err := mode.Validate(); err != nil {
+			fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(stderr, "configuration is valid")
+	}
+	if options.PrintConfig || options.Validate {
+		return exitOK
+	}
+	logger, err := mode.runtimeConfig().newLogger(stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+		return exitError
+	}
+	if _, err := mode.runtimeConfig().encoding(); err != nil {
+		fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+		return exitError
+	}
+	if _, err := mode.runtimeConfig().overrides(); err != nil {
+		fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+		return exitError
+	}
+	logging.SetDefault(logger)
+	log.SetFlags(0)
+	log.SetOutput(logger.Writer())
+	log.Print(mode.Info())
+	rt := newRuntime(mode.runtimeConfig())
+	defer rt.stop()
+	if err := mode.Validate(); err != nil {
+		log.Printf("invalid configuration: %v", err)
+		return exitError
+	}
+	rt.templates.Set()
+	rt.serve()
+	rt.handleSignals()
+	return runMode(mode, rt)
+}
+
+// runMode runs the mode until it stops or the runtime shuts down. On shutdown the mode has the grace period for draining and flushing
+func runMode(mode Mode, rt *Runtime) int {
+	result := make(chan error, 1)
+	go func() { result <- mode.Run(rt) }()
+	select {
+	case err := <-result:
+		return exitCode(err)
+	case <-rt.Context.Done():
+	}
+	select {
+	case err := <-result:
+		if code := exitCode(err); code != exitOK {
+			return code
+		}
+		log.Print("shutdown completed")
+		return exitOK
+	case <-time.After(rt.config.ShutdownGracePeriod + flushTimeout):
+		log.Printf("shutdown not completed within grace period of %v", rt.config.ShutdownGracePeriod)
+		return exitShutdownTimeout
+	}
+}
+
+func exitCode(err error) int {
+	if err == nil || err == context.Canceled {
+		return exitOK
+	}
+	log.Print(err)
+	return exitError
+}
+
+// sendMode depending on K_SINK env variable, directly reply with an event or send it to the sink
+func sendMode(sink string) string {
+	if sink == "" {
+		return "reply mode"
+	}
+	return "send mode"
+}
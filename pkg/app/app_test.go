@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cepipeline"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/config"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenMode    string
+		whenArgs     []string
+		thenExitCode int
+		thenStdout   string
+		thenStderr   string
+	}{
+		{name: "unknown mode", givenMode: "unknown", thenExitCode: 2, thenStderr: "unknown mode 'unknown'"},
+		{name: "print config", givenMode: "mapper", whenArgs: []string{"--print-config", "--ce-template", "{{ .data }}"},
+			thenStdout: `CE_TEMPLATE: "{{ .data }}"`},
+		{name: "validate", givenMode: "filter", whenArgs: []string{"--validate", "--ce-template", "{{ eq .data.name \"Alex\" }}"},
+			thenStderr: "configuration is valid"},
+		{name: "validate invalid template", givenMode: "mapper", whenArgs: []string{"--validate", "--ce-template", "{{ .data"},
+			thenExitCode: 1, thenStderr: "invalid configuration"},
+		{name: "validate invalid schedule", givenMode: "periodic-producer", whenArgs: []string{"--validate", "--schedule", "* *"},
+			thenExitCode: 1, thenStderr: "invalid schedule"},
+		{name: "validate missing file", givenMode: "file-producer", whenArgs: []string{"--validate", "--file", "notexisting.jsonl", "--k-sink", "http://localhost"},
+			thenExitCode: 1, thenStderr: "failed to read events"},
+		{name: "required key", givenMode: "grpc-client-mapper", whenArgs: []string{"--validate"},
+			thenExitCode: 2, thenStderr: "required key GRPC_TARGET missing value"},
+		{name: "unknown flag", givenMode: "recorder", whenArgs: []string{"--unknown"},
+			thenExitCode: 2, thenStderr: "flag provided but not defined"},
+		{name: "invalid log level", givenMode: "mapper", whenArgs: []string{"--log-level", "trace"},
+			thenExitCode: 1, thenStderr: "unknown log level 'trace'"},
+		{name: "invalid encoding", givenMode: "periodic-producer", whenArgs: []string{"--ce-encoding", "batch"},
+			thenExitCode: 1, thenStderr: "unknown encoding 'batch'"},
+		{name: "invalid ce overrides", givenMode: "mapper", whenArgs: []string{"--k-ce-overrides", `{"extensions": {"ten-ant": "a"}}`},
+			thenExitCode: 1, thenStderr: "invalid ce overrides extension 'ten-ant'"},
+		{name: "invalid extensions template", givenMode: "mapper", whenArgs: []string{"--validate", "--ce-extensions-template", "{{ .data"},
+			thenExitCode: 1, thenStderr: "invalid extensions template"},
+		{name: "dedup in reply mode", givenMode: "mapper", whenArgs: []string{"--validate", "--dedup"},
+			thenExitCode: 1, thenStderr: "DEDUP needs K_SINK"},
+		{name: "help", givenMode: "http-client-filter", whenArgs: []string{"-h"},
+			thenStderr: "-response-template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer unsetEnv(tt.givenMode)
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			exitCode := run(tt.givenMode, tt.whenArgs, stdout, stderr)
+			if exitCode != tt.thenExitCode {
+				t.Errorf("run() exit code = %v, want %v, stderr: %s", exitCode, tt.thenExitCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.thenStdout) {
+				t.Errorf("run() stdout = %s, want %s", stdout.String(), tt.thenStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.thenStderr) {
+				t.Errorf("run() stderr = %s, want %s", stderr.String(), tt.thenStderr)
+			}
+		})
+	}
+}
+
+// unsetEnv the configuration of a mode is passed by environment variables to envconfig
+func unsetEnv(name string) {
+	if !IsMode(name) {
+		return
+	}
+	keys, _ := config.Keys(modes[name]())
+	for _, key := range keys {
+		os.Unsetenv(key.Name)
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	if len(names) != len(modes) || names[0] != "file-producer" {
+		t.Errorf("Names() = %v", names)
+	}
+	for _, name := range names {
+		if !IsMode(name) {
+			t.Errorf("IsMode(%s) = false", name)
+		}
+	}
+}
+
+type modeMock struct {
+	RuntimeConfig
+	run func(rt *Runtime) error
+}
+
+func (m *modeMock) Info() string          { return "" }
+func (m *modeMock) Validate() error       { return nil }
+func (m *modeMock) Run(rt *Runtime) error { return m.run(rt) }
+
+func TestRunMode(t *testing.T) {
+	flushTimeout = 0
+	tests := []struct {
+		name         string
+		givenRun     func(rt *Runtime) error
+		whenShutdown bool
+		thenExitCode int
+	}{
+		{name: "finished", givenRun: func(rt *Runtime) error { return nil }, thenExitCode: exitOK},
+		{name: "failed", givenRun: func(rt *Runtime) error { return errors.New("failed") }, thenExitCode: exitError},
+		{name: "drained", givenRun: func(rt *Runtime) error {
+			<-rt.Context.Done()
+			time.Sleep(10 * time.Millisecond)
+			return rt.Context.Err()
+		}, whenShutdown: true, thenExitCode: exitOK},
+		{name: "failed on shutdown", givenRun: func(rt *Runtime) error {
+			<-rt.Context.Done()
+			return errors.New("flush failed")
+		}, whenShutdown: true, thenExitCode: exitError},
+		{name: "grace period exceeded", givenRun: func(rt *Runtime) error {
+			<-rt.Context.Done()
+			time.Sleep(time.Second)
+			return nil
+		}, whenShutdown: true, thenExitCode: exitShutdownTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode := &modeMock{RuntimeConfig: RuntimeConfig{ShutdownGracePeriod: 100 * time.Millisecond}, run: tt.givenRun}
+			rt := newRuntime(mode.runtimeConfig())
+			defer rt.stop()
+			if tt.whenShutdown {
+				rt.shutdown()
+				if err := rt.running.Check(); err == nil {
+					t.Error("runtime is ready after shutdown")
+				}
+			}
+			if exitCode := runMode(mode, rt); exitCode != tt.thenExitCode {
+				t.Errorf("runMode() exit code = %v, want %v", exitCode, tt.thenExitCode)
+			}
+		})
+	}
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	rt := newRuntime(&RuntimeConfig{})
+	pipeline, err := cepipeline.NewPipeline(cepipeline.Config{Steps: []cepipeline.StepConfig{{Name: "vip", Type: cepipeline.Filter, Template: "{{ eq .data.vip true }}"}}}, nil, false)
+	if err != nil {
+		t.Fatalf("NewPipeline() error = %v", err)
+	}
+	addPipelineGauges(rt, pipeline)
+	logged := make(chan struct{})
+	go func() {
+		logPipelineMetrics(rt.Context, pipeline, time.Millisecond)
+		close(logged)
+	}()
+	if _, err := pipeline.Process(context.Background(), cetransformer.NewEventWithJSONStringData(`{"vip": false}`, "source", "type")); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	rt.Health.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{`ce_go_template_pipeline_step_in{step="vip"} 1`, `ce_go_template_pipeline_step_dropped{step="vip"} 1`, `ce_go_template_pipeline_step_out{step="vip"} 0`} {
+		if !strings.Contains(recorder.Body.String(), want) {
+			t.Errorf("/metrics = %s, want %s", recorder.Body.String(), want)
+		}
+	}
+
+	rt.stop()
+	select {
+	case <-logged:
+	case <-time.After(time.Second):
+		t.Error("logPipelineMetrics() not stopped with the runtime")
+	}
+}
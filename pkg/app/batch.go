@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// BatchConfig groups produced events into batch requests to the sink
+type BatchConfig struct {
+	BatchMaxEvents  int           `split_words:"true" default:"0"`
+	BatchMaxDelay   time.Duration `split_words:"true" default:"100ms"`
+	BatchMaxRetries int           `split_words:"true" default:"5"`
+	BatchMinBackoff time.Duration `split_words:"true" default:"100ms"`
+}
+
+// validate a batch is acknowledged before it is sent, so it can't be combined with the outbox
+func (c *BatchConfig) validate(outbox *OutboxConfig) error {
+	if c.BatchMaxEvents <= 0 {
+		return nil
+	}
+	if c.BatchMaxDelay <= 0 {
+		return fmt.Errorf("invalid batch max delay: %v", c.BatchMaxDelay)
+	}
+	if c.BatchMaxRetries > 0 && c.BatchMinBackoff <= 0 {
+		return fmt.Errorf("invalid batch min backoff: %v", c.BatchMinBackoff)
+	}
+	if outbox.OutboxDir != "" {
+		return fmt.Errorf("batching can't be combined with the outbox")
+	}
+	return nil
+}
+
+// batchClient sends the events of the client in batches, only with BATCH_MAX_EVENTS. The returned function flushes the pending batch
+func (c *BatchConfig) batchClient(client cloudevents.Client, sink string, timeout time.Duration) (cloudevents.Client, func()) {
+	if c.BatchMaxEvents <= 0 {
+		return client, func() {}
+	}
+	batchSender := cehandler.NewBatchSender(client, sink, c.BatchMaxEvents, c.BatchMaxDelay, timeout).WithRetries(c.BatchMaxRetries, c.BatchMinBackoff)
+	return batchSender, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+		if err := batchSender.Close(ctx); err != nil {
+			log.Print(err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/dedup"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// DedupConfig drops redelivered events of a receiver
+type DedupConfig struct {
+	Dedup            bool          `default:"false"`
+	DedupTTL         time.Duration `split_words:"true" default:"10m"`
+	DedupKeyTemplate string        `split_words:"true" default:""`
+}
+
+// validate parses the key template
+func (c *DedupConfig) validate() error {
+	_, err := c.protocolOptions()
+	return err
+}
+
+// validateWithSink as validate, a mapper needs a sink because in reply mode a duplicate is answered without the mapped event
+func (c *DedupConfig) validateWithSink(sink string) error {
+	if c.Dedup && sink == "" {
+		return errors.New("DEDUP needs K_SINK, in reply mode a duplicate is answered without the mapped event")
+	}
+	return c.validate()
+}
+
+// protocolOptions responds to duplicates without processing them, only with DEDUP
+func (c *DedupConfig) protocolOptions() ([]cehttp.Option, error) {
+	if !c.Dedup {
+		return nil, nil
+	}
+	deduplicator, err := dedup.NewDeduplicator(dedup.NewMemoryStore(), c.DedupTTL, c.DedupKeyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup configuration: %v", err)
+	}
+	return []cehttp.Option{cehttp.WithMiddleware(deduplicator.Middleware)}, nil
+}
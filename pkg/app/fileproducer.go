@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cereplay"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// FileProducerConfig replays recorded events from a file
+type FileProducerConfig struct {
+	File           string        `required:"true"`
+	CeTemplate     string        `split_words:"true"`
+	CeSource       string        `split_words:"true"`
+	CeType         string        `split_words:"true"`
+	Sink           string        `envconfig:"K_SINK" required:"true"`
+	Timeout        time.Duration `default:"1000ms"`
+	OriginalTiming bool          `split_words:"true" default:"true"`
+	Speed          float64       `default:"1"`
+	Loop           bool          `default:"false"`
+	RuntimeConfig
+}
+
+// Info bla
+func (c *FileProducerConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Verbose: %v
+File: '%s'
+Original timing: %v
+Speed: %v
+Loop: %v
+Timeout: %v
+Sink: '%v'
+CeTemplate: '%v'
+CloudEvent source: %s
+CloudEvent type: %s`, c.Verbose, c.File, c.OriginalTiming, c.Speed, c.Loop, c.Timeout, c.Sink, c.CeTemplate, c.CeSource, c.CeType)
+}
+
+// eventProducer produces an event from a recorded event, optionally transformed by a template
+type eventProducer struct {
+	transformer *cetransformer.CloudEventTransformer
+}
+
+func (ep eventProducer) CreateEvent(input interface{}) (*cloudevents.Event, error) {
+	event := input.(cloudevents.Event)
+	if ep.transformer == nil {
+		return &event, nil
+	}
+	return ep.transformer.TransformEvent(&event)
+}
+
+func (c *FileProducerConfig) producer() (eventProducer, error) {
+	producer := eventProducer{}
+	if c.CeTemplate != "" {
+		transformer, err := cetransformer.NewCloudEventTransformer(c.CeTemplate, c.CeSource, c.CeType, c.Verbose)
+		if err != nil {
+			return producer, err
+		}
+		producer.transformer = transformer
+	}
+	return producer, nil
+}
+
+// Validate reads the events and parses the template
+func (c *FileProducerConfig) Validate() error {
+	if _, err := cereplay.ReadEvents(c.File); err != nil {
+		return fmt.Errorf("failed to read events: %v", err)
+	}
+	_, err := c.producer()
+	return err
+}
+
+// Run replays the events and returns when all events are sent or the runtime shuts down
+func (c *FileProducerConfig) Run(rt *Runtime) error {
+	events, err := cereplay.ReadEvents(c.File)
+	if err != nil {
+		return fmt.Errorf("failed to read events: %v", err)
+	}
+	log.Printf("read %d events from '%s'", len(events), c.File)
+
+	producer, err := c.producer()
+	if err != nil {
+		return err
+	}
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+
+	httpProtocol, err := cloudevents.NewHTTP(http.WithShutdownTimeout(c.Timeout))
+	if err != nil {
+		return fmt.Errorf("failed to create protocol: %v", err)
+	}
+	ceClient, err := rt.newClient(httpProtocol)
+	if err != nil {
+		return err
+	}
+	if ceClient, err = rt.withOverrides(ceClient); err != nil {
+		return err
+	}
+
+	ceProducerHandler := cehandler.NewProducerHandler(producer, ceClient, c.Sink, c.Timeout, c.Verbose)
+	replayer := cereplay.NewReplayer(cereplay.Config{OriginalTiming: c.OriginalTiming, Speed: c.Speed, Loop: c.Loop})
+	sent := 0
+	err = replayer.Run(rt.Context, events, func(event cloudevents.Event) error {
+		if result := ceProducerHandler.SendCe(event); result != nil {
+			log.Print(result)
+		} else {
+			sent++
+		}
+		return nil
+	})
+	if err == context.Canceled {
+		log.Printf("replay interrupted, %d events sent successfully", sent)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("replay finished, %d events sent successfully", sent)
+	return nil
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+)
+
+// FilterConfig filters events based on a go template
+type FilterConfig struct {
+	CeTemplate string `split_words:"true" default:"true"`
+	CePort     int    `split_words:"true" default:"8080"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+// Info bla
+func (c *FilterConfig) Info() string {
+	return fmt.Sprintf(`Configuration:
+====================================
+Verbose: %v
+Listening on Port: %v
+CeTemplate: '%v'`, c.Verbose, c.CePort, c.CeTemplate)
+}
+
+// Validate bla
+func (c *FilterConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validate(); err != nil {
+		return err
+	}
+	_, err := cetransformer.NewCloudEventTransformer(c.CeTemplate, "", "", c.Verbose)
+	return err
+}
+
+// Run bla
+func (c *FilterConfig) Run(rt *Runtime) error {
+	ceTransformer, err := cetransformer.NewCloudEventTransformer(c.CeTemplate, "", "", c.Verbose)
+	if err != nil {
+		return fmt.Errorf("failed to create transformer: %v", err)
+	}
+
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = cehandler.NewCeFilterHandler(rt.Context, ceTransformer, ceClient, c.Verbose)
+	return err
+}
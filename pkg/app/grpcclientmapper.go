@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cegrpcclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// GRPCClientMapperConfig transforms an event to a gRPC request, the response message is transformed to the outgoing event
+type GRPCClientMapperConfig struct {
+	GrpcTarget            string            `split_words:"true" required:"true"`
+	GrpcMethod            string            `split_words:"true" required:"true"`
+	GrpcDescriptorSetFile string            `split_words:"true"`
+	GrpcTLS               bool              `split_words:"true" default:"false"`
+	GrpcTimeout           time.Duration     `split_words:"true" default:"1000ms"`
+	GrpcMetadata          map[string]string `split_words:"true"`
+	RequestTemplate       string            `split_words:"true" default:"{{ .data | toJson }}"`
+	ResponseTemplate      string            `split_words:"true" default:"{{ .grpcresponse | toJson }}"`
+	CeSource              string            `split_words:"true"`
+	CeType                string            `split_words:"true"`
+	CePort                int               `split_words:"true" default:"8080"`
+	Sink                  string            `envconfig:"K_SINK"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+func (c *GRPCClientMapperConfig) descriptorSource() string {
+	if c.GrpcDescriptorSetFile == "" {
+		return "server reflection"
+	}
+	return c.GrpcDescriptorSetFile
+}
+
+// Info bla
+func (c *GRPCClientMapperConfig) Info() string {
+	return fmt.Sprintf(`Configuration:
+====================================
+Verbose: %v
+Sink: %v (using %s)
+gRPC target: '%s' tls: %v
+gRPC method: '%s'
+gRPC descriptors: %s
+gRPC timeout: %v
+Request template: '%s'
+Response template: '%s'
+cloudEvent source: '%s'
+cloudEvent type: '%s'
+Serving on Port: %v`, c.Verbose, c.Sink, sendMode(c.Sink), c.GrpcTarget, c.GrpcTLS, c.GrpcMethod, c.descriptorSource(), c.GrpcTimeout, c.RequestTemplate, c.ResponseTemplate, c.CeSource, c.CeType, c.CePort)
+}
+
+// Validate parses the templates and the method name, the method descriptor is only resolved with a descriptor set file
+func (c *GRPCClientMapperConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validateWithSink(c.Sink); err != nil {
+		return err
+	}
+	if _, err := transformer.NewTransformer(c.RequestTemplate, nil, c.Verbose); err != nil {
+		return fmt.Errorf("invalid request template: %v", err)
+	}
+	if _, err := transformer.NewTransformer(c.ResponseTemplate, nil, c.Verbose); err != nil {
+		return fmt.Errorf("invalid response template: %v", err)
+	}
+	if _, _, err := cegrpcclienttransformer.ParseMethodName(c.GrpcMethod); err != nil {
+		return err
+	}
+	if c.GrpcDescriptorSetFile != "" {
+		if _, err := cegrpcclienttransformer.MethodFromDescriptorSetFile(c.GrpcDescriptorSetFile, c.GrpcMethod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run bla
+func (c *GRPCClientMapperConfig) Run(rt *Runtime) error {
+	transportOption := grpc.WithInsecure()
+	if c.GrpcTLS {
+		transportOption = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	conn, err := grpc.Dial(c.GrpcTarget, transportOption)
+	if err != nil {
+		return fmt.Errorf("failed to connect to gRPC target: %v", err)
+	}
+	defer conn.Close()
+
+	method, err := c.methodDescriptor(conn)
+	if err != nil {
+		return fmt.Errorf("failed to find gRPC method: %v", err)
+	}
+
+	transformer, err := cegrpcclienttransformer.NewCeGRPCClientTransformer(conn, method, cegrpcclienttransformer.Config{RequestTemplate: c.RequestTemplate,
+		ResponseTemplate: c.ResponseTemplate, Timeout: c.GrpcTimeout, Metadata: c.GrpcMetadata, Source: c.CeSource, Type: c.CeType, Debug: c.Verbose})
+	if err != nil {
+		return fmt.Errorf("failed to create CeGRPCClientTransformer: %v", err)
+	}
+
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = cehandler.NewCeMapperHandler(rt.Context, transformer, ceClient, c.Sink, c.Verbose)
+	return err
+}
+
+func (c *GRPCClientMapperConfig) methodDescriptor(conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	if c.GrpcDescriptorSetFile != "" {
+		return cegrpcclienttransformer.MethodFromDescriptorSetFile(c.GrpcDescriptorSetFile, c.GrpcMethod)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*c.GrpcTimeout)
+	defer cancel()
+	return cegrpcclienttransformer.MethodFromReflection(ctx, conn, c.GrpcMethod)
+}
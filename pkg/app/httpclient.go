@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/limiter"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// HTTPClientConfig configuration shared by http-client-mapper and http-client-filter
+type HTTPClientConfig struct {
+	RequestTemplate          string            `split_words:"true" default:""`
+	RequestSteps             string            `split_words:"true" default:""`
+	HTTPTimeout              time.Duration     `split_words:"true" default:"1000ms"`
+	HTTPJsonBody             bool              `split_words:"true" default:"true"`
+	GraphqlURL               string            `split_words:"true"`
+	GraphqlQueryFile         string            `split_words:"true"`
+	GraphqlVariablesTemplate string            `split_words:"true" default:"{}"`
+	GraphqlHeaders           map[string]string `split_words:"true"`
+	GraphqlFailOnErrors      bool              `split_words:"true" default:"true"`
+	HTTPCacheSize            int               `split_words:"true" default:"0"`
+	HTTPCacheTTL             time.Duration     `split_words:"true" default:"60s"`
+	HTTPCacheHeaders         []string          `split_words:"true"`
+	CircuitBreakerFailures   int               `split_words:"true" default:"0"`
+	CircuitBreakerCooldown   time.Duration     `split_words:"true" default:"30s"`
+	HTTPRateLimit            float64           `split_words:"true" default:"0"`
+	HTTPRateLimitBurst       int               `split_words:"true" default:"1"`
+	HTTPRateLimitKeyTemplate string            `split_words:"true" default:""`
+	CePort                   int               `split_words:"true" default:"8080"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+func (c *HTTPClientConfig) info(responseTemplate string) string {
+	return fmt.Sprintf(`Verbose: %v
+Request template: '%s'
+Request steps: '%s'
+GraphQL url: '%s' query file: '%s' variables template: '%s'
+Response template: '%s'
+HTTP Request timeout: %v
+HTTP response has json body: %v
+HTTP response cache size: %v ttl: %v headers: %v
+Circuit breaker failures: %v cooldown: %v
+HTTP rate limit: %v/s burst: %v key template: '%s'
+Serving on Port: %v`, c.Verbose, c.RequestTemplate, c.RequestSteps, c.GraphqlURL, c.GraphqlQueryFile, c.GraphqlVariablesTemplate, responseTemplate, c.HTTPTimeout, c.HTTPJsonBody, c.HTTPCacheSize, c.HTTPCacheTTL, c.HTTPCacheHeaders, c.CircuitBreakerFailures, c.CircuitBreakerCooldown, c.HTTPRateLimit, c.HTTPRateLimitBurst, c.HTTPRateLimitKeyTemplate, c.CePort)
+}
+
+func (c *HTTPClientConfig) newTransformer(responseTemplate string) (*cehttpclienttransformer.CeHTTPClientTransformer, error) {
+	transformer, err := c.newBaseTransformer(responseTemplate)
+	if err != nil || c.HTTPRateLimit <= 0 {
+		return transformer, err
+	}
+	rateLimiter, err := limiter.NewRateLimiter(c.HTTPRateLimit, c.HTTPRateLimitBurst, c.HTTPRateLimitKeyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http rate limit: %v", err)
+	}
+	return transformer.WithRateLimiter(rateLimiter), nil
+}
+
+func (c *HTTPClientConfig) newBaseTransformer(responseTemplate string) (*cehttpclienttransformer.CeHTTPClientTransformer, error) {
+	if c.GraphqlURL != "" {
+		query, err := ioutil.ReadFile(c.GraphqlQueryFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read GraphQL query file: %v", err)
+		}
+		graphQL := cehttpclienttransformer.GraphQL{URL: c.GraphqlURL, Query: string(query), VariablesTemplate: c.GraphqlVariablesTemplate, Headers: c.GraphqlHeaders, FailOnErrors: c.GraphqlFailOnErrors}
+		return cehttpclienttransformer.NewCeHTTPClientGraphQLTransformer(graphQL, responseTemplate, c.HTTPTimeout, c.Verbose)
+	}
+	if c.RequestSteps == "" {
+		return cehttpclienttransformer.NewCeHTTPClientTransformer(c.RequestTemplate, responseTemplate, c.HTTPTimeout, c.HTTPJsonBody, c.Verbose)
+	}
+	steps, err := cehttpclienttransformer.ParseSteps(c.RequestSteps)
+	if err != nil {
+		return nil, err
+	}
+	return cehttpclienttransformer.NewCeHTTPClientChainTransformer(steps, responseTemplate, c.HTTPTimeout, c.HTTPJsonBody, c.Verbose)
+}
+
+// start creates the transformer and the client, the handler is started by startHandler
+func (c *HTTPClientConfig) start(rt *Runtime, responseTemplate string, startHandler func(*cehttpclienttransformer.CeHTTPClientTransformer, cloudevents.Client) error) error {
+	transformer, err := c.newTransformer(responseTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to create CeHTTPClientTransformer: %v", err)
+	}
+	// the breaker is installed first, so the cache wraps it and cached responses are served while a circuit is open
+	if c.CircuitBreakerFailures > 0 {
+		breaker := cehttpclienttransformer.NewCircuitBreaker(c.CircuitBreakerFailures, c.CircuitBreakerCooldown)
+		transformer.WithCircuitBreaker(breaker)
+		rt.checkCircuitBreaker(breaker)
+	}
+	if c.HTTPCacheSize > 0 {
+		transformer.WithResponseCache(cehttpclienttransformer.NewResponseCache(c.HTTPCacheSize, c.HTTPCacheTTL, c.HTTPCacheHeaders))
+		addCacheGauges(rt, transformer)
+		go logCacheStats(rt.Context, transformer)
+	}
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+	return startHandler(transformer, ceClient)
+}
+
+func addCacheGauges(rt *Runtime, transformer *cehttpclienttransformer.CeHTTPClientTransformer) {
+	rt.Health.AddGauge("ce_go_template_http_cache_hits", "responses served from the cache", func() float64 { return float64(transformer.CacheStats().Hits) })
+	rt.Health.AddGauge("ce_go_template_http_cache_misses", "requests not found in the cache", func() float64 { return float64(transformer.CacheStats().Misses) })
+	rt.Health.AddGauge("ce_go_template_http_cache_revalidated", "stale responses revalidated by the server", func() float64 { return float64(transformer.CacheStats().Revalidated) })
+	rt.Health.AddGauge("ce_go_template_http_cache_entries", "responses in the cache", func() float64 { return float64(transformer.CacheStats().Entries) })
+}
+
+func logCacheStats(ctx context.Context, transformer *cehttpclienttransformer.CeHTTPClientTransformer) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := transformer.CacheStats()
+			log.Printf("HTTP response cache: hits=%d misses=%d revalidated=%d entries=%d", stats.Hits, stats.Misses, stats.Revalidated, stats.Entries)
+		}
+	}
+}
+
+// HTTPClientMapperConfig transforms an event to a http request, the response is transformed to the outgoing event
+type HTTPClientMapperConfig struct {
+	HTTPClientConfig
+	ResponseTemplate string `split_words:"true" default:"{{ .httpresponse.body | toJson }}"`
+	Sink             string `envconfig:"K_SINK"`
+}
+
+// Info bla
+func (c *HTTPClientMapperConfig) Info() string {
+	return fmt.Sprintf(`Configuration:
+====================================
+Sink: %v (using %s)
+%s`, c.Sink, sendMode(c.Sink), c.info(c.ResponseTemplate))
+}
+
+// Validate bla
+func (c *HTTPClientMapperConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validateWithSink(c.Sink); err != nil {
+		return err
+	}
+	_, err := c.newTransformer(c.ResponseTemplate)
+	return err
+}
+
+// Run bla
+func (c *HTTPClientMapperConfig) Run(rt *Runtime) error {
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+	return c.start(rt, c.ResponseTemplate, func(transformer *cehttpclienttransformer.CeHTTPClientTransformer, ceClient cloudevents.Client) error {
+		_, err := cehandler.NewCeMapperHandler(rt.Context, transformer, ceClient, c.Sink, c.Verbose)
+		return err
+	})
+}
+
+// HTTPClientFilterConfig transforms an event to a http request, the response is transformed to a predicate
+type HTTPClientFilterConfig struct {
+	HTTPClientConfig
+	ResponseTemplate string `split_words:"true" default:"true"`
+}
+
+// Info bla
+func (c *HTTPClientFilterConfig) Info() string {
+	return fmt.Sprintf(`Configuration:
+====================================
+%s`, c.info(c.ResponseTemplate))
+}
+
+// Validate bla
+func (c *HTTPClientFilterConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validate(); err != nil {
+		return err
+	}
+	_, err := c.newTransformer(c.ResponseTemplate)
+	return err
+}
+
+// Run bla
+func (c *HTTPClientFilterConfig) Run(rt *Runtime) error {
+	return c.start(rt, c.ResponseTemplate, func(transformer *cehttpclienttransformer.CeHTTPClientTransformer, ceClient cloudevents.Client) error {
+		_, err := cehandler.NewCeFilterHandler(rt.Context, transformer, ceClient, c.Verbose)
+		return err
+	})
+}
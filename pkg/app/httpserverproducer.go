@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cehttpserver"
+	"github.com/alitari/ce-go-template/pkg/cerequesttransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// HTTPServerProducerConfig sends events based on incoming http requests
+type HTTPServerProducerConfig struct {
+	CeTemplate string        `split_words:"true" default:"{\"name\":\"Alex\"}"`
+	CeSource   string        `split_words:"true" default:"https://github.com/alitari/ce-go-template"`
+	CeType     string        `split_words:"true" default:"com.github.alitari.ce-go-template.periodic-producer"`
+	Sink       string        `envconfig:"K_SINK"`
+	Timeout    time.Duration `default:"1000ms"`
+	HTTPPort   int           `split_words:"true" default:"8080"`
+	HTTPPath   string        `split_words:"true" default:"/"`
+	HTTPMethod string        `split_words:"true" default:"GET"`
+	HTTPAccept string        `split_words:"true" default:"application/json"`
+	OutboxConfig
+	BatchConfig
+	RuntimeConfig
+}
+
+// Info bla
+func (c *HTTPServerProducerConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Verbose: %v
+Timeout: %v
+Sink: '%v
+CeTemplate: '%v'
+CloudEvent source: %s
+CloudEvent type: %s
+Serving HTTP %s on path '%s' listening on port %v accepting '%s'`, c.Verbose, c.Timeout, c.Sink, c.CeTemplate, c.CeSource, c.CeType, c.HTTPMethod, c.HTTPPath, c.HTTPPort, c.HTTPAccept)
+}
+
+// Validate bla
+func (c *HTTPServerProducerConfig) Validate() error {
+	if _, err := cerequesttransformer.NewRequestTransformer(c.CeTemplate, c.CeType, c.CeSource, c.Verbose); err != nil {
+		return err
+	}
+	return c.BatchConfig.validate(&c.OutboxConfig)
+}
+
+// Run bla
+func (c *HTTPServerProducerConfig) Run(rt *Runtime) error {
+	httpProtocol, err := cloudevents.NewHTTP(cehttp.WithShutdownTimeout(c.Timeout))
+	if err != nil {
+		return fmt.Errorf("failed to create protocol: %v", err)
+	}
+	ceClient, err := rt.newClient(httpProtocol)
+	if err != nil {
+		return err
+	}
+	ceClient, flushBatch := c.batchClient(ceClient, c.Sink, c.Timeout)
+	defer flushBatch()
+	if ceClient, err = rt.withOverrides(ceClient); err != nil {
+		return err
+	}
+
+	ceProducer, err := cerequesttransformer.NewRequestTransformer(c.CeTemplate, c.CeType, c.CeSource, c.Verbose)
+	if err != nil {
+		return fmt.Errorf("failed to create request transformer: %v", err)
+	}
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+	rt.checkReceiver(c.HTTPPort)
+	ceProducerHandler := cehandler.NewProducerHandler(ceProducer, ceClient, c.Sink, c.Timeout, c.Verbose)
+	if err := c.startOutbox(rt, ceProducerHandler); err != nil {
+		return err
+	}
+	server := cehttpserver.NewCeHTTPServer(c.HTTPPort, c.HTTPPath, c.HTTPMethod, c.Verbose, ceProducerHandler)
+
+	<-rt.Context.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), rt.config.ShutdownGracePeriod)
+	defer cancel()
+	return server.ShutDown(ctx)
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/limiter"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// LimitConfig limits the events in flight of a receiver and the rate of events sent to the sink
+type LimitConfig struct {
+	MaxInFlight              int           `split_words:"true" default:"0"`
+	QueueTimeout             time.Duration `split_words:"true" default:"0s"`
+	RetryAfter               time.Duration `split_words:"true" default:"1s"`
+	SendRateLimit            float64       `split_words:"true" default:"0"`
+	SendRateLimitBurst       int           `split_words:"true" default:"1"`
+	SendRateLimitKeyTemplate string        `split_words:"true" default:""`
+}
+
+// validate parses the key template
+func (c *LimitConfig) validate() error {
+	_, err := c.sendRateLimiter()
+	return err
+}
+
+// sendRateLimiter nil without SEND_RATE_LIMIT
+func (c *LimitConfig) sendRateLimiter() (*limiter.RateLimiter, error) {
+	if c.SendRateLimit <= 0 {
+		return nil, nil
+	}
+	rateLimiter, err := limiter.NewRateLimiter(c.SendRateLimit, c.SendRateLimitBurst, c.SendRateLimitKeyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid send rate limit: %v", err)
+	}
+	return rateLimiter, nil
+}
+
+// protocolOptions responds with 429 if MAX_IN_FLIGHT events are processed
+func (c *LimitConfig) protocolOptions() []cehttp.Option {
+	if c.MaxInFlight <= 0 {
+		return nil
+	}
+	concurrencyLimiter := limiter.NewConcurrencyLimiter(c.MaxInFlight, c.QueueTimeout)
+	return []cehttp.Option{cehttp.WithMiddleware(concurrencyLimiter.Middleware(c.RetryAfter))}
+}
+
+// limitClient waits for SEND_RATE_LIMIT before an event is sent
+func (c *LimitConfig) limitClient(client cloudevents.Client) (cloudevents.Client, error) {
+	rateLimiter, err := c.sendRateLimiter()
+	if err != nil || rateLimiter == nil {
+		return client, err
+	}
+	return limiter.NewRateLimitedClient(client, rateLimiter), nil
+}
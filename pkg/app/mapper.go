@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+)
+
+// MapperConfig transforms events based on a go template
+type MapperConfig struct {
+	CeTemplate           string `split_words:"true" default:"{{ toJson .data }}"`
+	CeExtensionsTemplate string `split_words:"true"`
+	CeSource             string `split_words:"true" default:"https://github.com/alitari/ce-go-template"`
+	CeType               string `split_words:"true" default:"com.github.alitari.ce-go-template.mapper"`
+	CePort               int    `split_words:"true" default:"8080"`
+	Sink                 string `envconfig:"K_SINK"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+// Info bla
+func (c *MapperConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Verbose: %v
+Listening on port: %v
+Sink: %v (using %s)
+cloudEvent source: '%s'
+cloudEvent type: '%s'
+CeTemplate: '%v'
+CeExtensionsTemplate: '%v'`, c.Verbose, c.CePort, c.Sink, sendMode(c.Sink), c.CeSource, c.CeType, c.CeTemplate, c.CeExtensionsTemplate)
+}
+
+// Validate bla
+func (c *MapperConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validateWithSink(c.Sink); err != nil {
+		return err
+	}
+	_, err := cetransformer.NewCloudEventTransformerWithExtensions(c.CeTemplate, c.CeExtensionsTemplate, c.CeSource, c.CeType, c.Verbose)
+	return err
+}
+
+// Run bla
+func (c *MapperConfig) Run(rt *Runtime) error {
+	ceTransformer, err := cetransformer.NewCloudEventTransformerWithExtensions(c.CeTemplate, c.CeExtensionsTemplate, c.CeSource, c.CeType, c.Verbose)
+	if err != nil {
+		return fmt.Errorf("failed to create transformer: %v", err)
+	}
+
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = cehandler.NewCeMapperHandler(rt.Context, ceTransformer, ceClient, c.Sink, c.Verbose)
+	return err
+}
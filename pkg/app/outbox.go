@@ -0,0 +1,33 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/outbox"
+)
+
+// OutboxConfig persists produced events in a directory until they are delivered
+type OutboxConfig struct {
+	OutboxDir        string        `split_words:"true" default:""`
+	OutboxMinBackoff time.Duration `split_words:"true" default:"1s"`
+	OutboxMaxBackoff time.Duration `split_words:"true" default:"1m"`
+}
+
+// startOutbox the handler writes events to the outbox, which delivers them until the runtime shuts down. Without OUTBOX_DIR the handler sends directly
+func (c *OutboxConfig) startOutbox(rt *Runtime, handler *cehandler.CeProducerHandler) error {
+	if c.OutboxDir == "" {
+		return nil
+	}
+	eventOutbox, err := outbox.Open(c.OutboxDir, c.OutboxMinBackoff, c.OutboxMaxBackoff)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %v", err)
+	}
+	log.Printf("outbox '%s' contains %d events", c.OutboxDir, eventOutbox.Depth())
+	rt.Health.AddGauge("ce_go_template_outbox_depth", "number of events in the outbox", func() float64 { return float64(eventOutbox.Depth()) })
+	handler.WithOutbox(eventOutbox)
+	go eventOutbox.Run(rt.Context, handler.Deliver)
+	return nil
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/loadgen"
+	"github.com/alitari/ce-go-template/pkg/scheduler"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// PeriodicProducerConfig sends events periodically or generates load
+type PeriodicProducerConfig struct {
+	CeTemplate           string        `split_words:"true" default:"{\"name\":\"Alex\"}"`
+	CeExtensionsTemplate string        `split_words:"true"`
+	CeSource             string        `split_words:"true" default:"https://github.com/alitari/ce-go-template"`
+	CeType               string        `split_words:"true" default:"com.github.alitari.ce-go-template.periodic-producer"`
+	Sink                 string        `envconfig:"K_SINK"`
+	Timeout              time.Duration `default:"1000ms"`
+	Period               time.Duration `default:"1000ms"`
+	Schedule             string
+	ScheduleTimezone     string        `split_words:"true" default:"UTC"`
+	ScheduleStart        time.Time     `split_words:"true"`
+	ScheduleEnd          time.Time     `split_words:"true"`
+	Jitter               time.Duration `default:"0s"`
+	MaxEvents            uint64        `split_words:"true" default:"0"`
+	RunOnStart           bool          `split_words:"true" default:"false"`
+	InputEnv             []string      `split_words:"true"`
+	DataFile             string        `split_words:"true"`
+	Mode                 string        `default:"periodic"`
+	LoadRate             float64       `split_words:"true" default:"100"`
+	LoadConcurrency      int           `split_words:"true" default:"10"`
+	LoadRampUp           time.Duration `split_words:"true" default:"0s"`
+	LoadRampSteps        int           `split_words:"true" default:"0"`
+	LoadDuration         time.Duration `split_words:"true" default:"1m"`
+	LoadTemplatesFile    string        `split_words:"true"`
+	OutboxConfig
+	BatchConfig
+	RuntimeConfig
+}
+
+func (c *PeriodicProducerConfig) schedule() string {
+	if c.Schedule == "" {
+		return fmt.Sprintf("every %v", c.Period)
+	}
+	return fmt.Sprintf("'%s' (%s)", c.Schedule, c.ScheduleTimezone)
+}
+
+// Info bla
+func (c *PeriodicProducerConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Mode: %s
+Verbose: %v
+Schedule: %s
+Schedule window: %v - %v
+Jitter: %v
+Max events: %v
+Run on start: %v
+Input env: %v
+Data file: '%s'
+Timeout: %v
+Sink: '%v'
+CeTemplate: '%v'
+CeExtensionsTemplate: '%v'
+CloudEvent source: %s
+CloudEvent type: %s%s`, c.Mode, c.Verbose, c.schedule(), c.ScheduleStart, c.ScheduleEnd, c.Jitter, c.MaxEvents, c.RunOnStart, c.InputEnv, c.DataFile, c.Timeout, c.Sink, c.CeTemplate, c.CeExtensionsTemplate, c.CeSource, c.CeType, c.loadInfo())
+}
+
+func (c *PeriodicProducerConfig) loadInfo() string {
+	if c.Mode != "load" {
+		return ""
+	}
+	return fmt.Sprintf(`
+Load rate: %v events/s
+Load concurrency: %v
+Load ramp-up: %v in %d steps
+Load duration: %v
+Load templates file: '%s'`, c.LoadRate, c.LoadConcurrency, c.LoadRampUp, c.LoadRampSteps, c.LoadDuration, c.LoadTemplatesFile)
+}
+
+func (c *PeriodicProducerConfig) loadConfig() loadgen.Config {
+	return loadgen.Config{Rate: c.LoadRate, Concurrency: c.LoadConcurrency, RampUp: c.LoadRampUp, RampSteps: c.LoadRampSteps,
+		Duration: c.LoadDuration, MaxEvents: c.MaxEvents, Timeout: c.Timeout}
+}
+
+// producer a single transformer for CE_TEMPLATE or a weighted choice of the templates in LOAD_TEMPLATES_FILE
+func (c *PeriodicProducerConfig) producer() (cehandler.CeProducer, error) {
+	if c.LoadTemplatesFile == "" {
+		return cetransformer.NewCloudEventTransformerWithExtensions(c.CeTemplate, c.CeExtensionsTemplate, c.CeSource, c.CeType, c.Verbose)
+	}
+	templates, err := loadgen.ReadTemplatesFile(c.LoadTemplatesFile)
+	if err != nil {
+		return nil, err
+	}
+	producers := []loadgen.Weighted{}
+	for _, t := range templates {
+		ceType := t.Type
+		if ceType == "" {
+			ceType = c.CeType
+		}
+		ceTransformer, err := cetransformer.NewCloudEventTransformerWithExtensions(t.Template, c.CeExtensionsTemplate, c.CeSource, ceType, c.Verbose)
+		if err != nil {
+			return nil, err
+		}
+		producers = append(producers, loadgen.Weighted{Weight: t.Weight, Producer: ceTransformer})
+	}
+	return loadgen.NewWeightedProducer(producers)
+}
+
+func (c *PeriodicProducerConfig) schedulerConfig() (scheduler.Config, error) {
+	var schedule scheduler.Schedule = scheduler.PeriodSchedule{Period: c.Period}
+	if c.Schedule != "" {
+		loc, err := time.LoadLocation(c.ScheduleTimezone)
+		if err != nil {
+			return scheduler.Config{}, err
+		}
+		if schedule, err = scheduler.ParseSchedule(c.Schedule, loc); err != nil {
+			return scheduler.Config{}, err
+		}
+	}
+	return scheduler.Config{Schedule: schedule, Start: c.ScheduleStart, End: c.ScheduleEnd, Jitter: c.Jitter, MaxEvents: c.MaxEvents, RunOnStart: c.RunOnStart}, nil
+}
+
+// Validate parses the schedule and the templates and reads the data file
+func (c *PeriodicProducerConfig) Validate() error {
+	if _, err := c.schedulerConfig(); err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
+	}
+	if _, err := scheduler.NewInputSource(c.InputEnv, c.DataFile); err != nil {
+		return fmt.Errorf("failed to create input: %v", err)
+	}
+	if _, err := c.producer(); err != nil {
+		return err
+	}
+	if err := c.BatchConfig.validate(&c.OutboxConfig); err != nil {
+		return err
+	}
+	if c.Mode == "load" {
+		return c.loadConfig().Validate()
+	}
+	return nil
+}
+
+// Run in load mode returns after the report is logged, the periodic mode runs until the runtime shuts down
+func (c *PeriodicProducerConfig) Run(rt *Runtime) error {
+	schedulerConfig, err := c.schedulerConfig()
+	if err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
+	}
+
+	inputSource, err := scheduler.NewInputSource(c.InputEnv, c.DataFile)
+	if err != nil {
+		return fmt.Errorf("failed to create input: %v", err)
+	}
+
+	httpProtocol, err := cloudevents.NewHTTP(http.WithShutdownTimeout(c.Timeout))
+	if err != nil {
+		return fmt.Errorf("failed to create protocol: %v", err)
+	}
+	ceClient, err := rt.newClient(httpProtocol)
+	if err != nil {
+		return err
+	}
+	ceClient, flushBatch := c.batchClient(ceClient, c.Sink, c.Timeout)
+	defer flushBatch()
+	if ceClient, err = rt.withOverrides(ceClient); err != nil {
+		return err
+	}
+
+	producer, err := c.producer()
+	if err != nil {
+		return err
+	}
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+
+	if c.Mode == "load" {
+		generator, err := loadgen.NewGenerator(c.loadConfig(), producer, ceClient, c.Sink, func(sequence uint64, t time.Time) interface{} {
+			return inputSource.Input(scheduler.Tick{Time: t, Count: sequence})
+		})
+		if err != nil {
+			return fmt.Errorf("invalid load configuration: %v", err)
+		}
+		log.Print(generator.Run(rt.Context))
+		return nil
+	}
+
+	ceProducerHandler := cehandler.NewProducerHandler(producer, ceClient, c.Sink, c.Timeout, c.Verbose)
+	if err := c.startOutbox(rt, ceProducerHandler); err != nil {
+		return err
+	}
+
+	err = scheduler.NewScheduler(schedulerConfig).Run(rt.Context, func(tick scheduler.Tick) {
+		result := ceProducerHandler.SendCe(inputSource.Input(tick))
+		if result != nil {
+			log.Print(result)
+		}
+	})
+	if err != nil {
+		log.Printf("schedule stopped: %v", err)
+		return err
+	}
+	log.Print("schedule finished")
+	<-rt.Context.Done()
+	return nil
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cepipeline"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// PipelineConfig runs events through the steps of a pipeline file in one process
+type PipelineConfig struct {
+	PipelineFile    string        `split_words:"true" required:"true"`
+	MetricsInterval time.Duration `split_words:"true" default:"1m"`
+	CePort          int           `split_words:"true" default:"8080"`
+	Sink            string        `envconfig:"K_SINK"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+// Info bla
+func (c *PipelineConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Verbose: %v
+Listening on port: %v
+Sink: %v (using %s)
+Pipeline file: '%s'
+Metrics interval: %v`, c.Verbose, c.CePort, c.Sink, sendMode(c.Sink), c.PipelineFile, c.MetricsInterval)
+}
+
+// Validate reads the pipeline file and parses all templates
+func (c *PipelineConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validateWithSink(c.Sink); err != nil {
+		return err
+	}
+	config, err := cepipeline.ReadConfig(c.PipelineFile)
+	if err != nil {
+		return err
+	}
+	_, err = cepipeline.NewPipeline(config, func(ctx context.Context, sink string, event cloudevents.Event) error { return nil }, c.Verbose)
+	return err
+}
+
+// Run bla
+func (c *PipelineConfig) Run(rt *Runtime) error {
+	config, err := cepipeline.ReadConfig(c.PipelineFile)
+	if err != nil {
+		return err
+	}
+
+	if err := rt.checkSink(c.Sink); err != nil {
+		return err
+	}
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := cepipeline.NewPipeline(config, func(ctx context.Context, sink string, event cloudevents.Event) error {
+		if result := ceClient.Send(cloudevents.ContextWithTarget(ctx, sink), event); !cloudevents.IsACK(result) {
+			return result
+		}
+		return nil
+	}, c.Verbose)
+	if err != nil {
+		return fmt.Errorf("failed to create pipeline: %v", err)
+	}
+	addPipelineGauges(rt, pipeline)
+	if c.MetricsInterval > 0 {
+		go logPipelineMetrics(rt.Context, pipeline, c.MetricsInterval)
+	}
+
+	_, err = cepipeline.NewCePipelineHandler(rt.Context, pipeline, ceClient, c.Sink, c.Verbose)
+	return err
+}
+
+func addPipelineGauges(rt *Runtime, pipeline *cepipeline.Pipeline) {
+	counters := []struct {
+		name  string
+		help  string
+		value func(cepipeline.StepMetrics) float64
+	}{
+		{"in", "events processed by the step", func(m cepipeline.StepMetrics) float64 { return float64(m.In) }},
+		{"out", "events created by the step", func(m cepipeline.StepMetrics) float64 { return float64(m.Out) }},
+		{"dropped", "events dropped by the step", func(m cepipeline.StepMetrics) float64 { return float64(m.Dropped) }},
+		{"errors", "failed executions of the step", func(m cepipeline.StepMetrics) float64 { return float64(m.Errors) }},
+		{"duration_seconds", "total processing time of the step", func(m cepipeline.StepMetrics) float64 { return m.Duration.Seconds() }},
+	}
+	for _, counter := range counters {
+		for i, metrics := range pipeline.Metrics() {
+			i, value := i, counter.value
+			rt.Health.AddGauge(fmt.Sprintf("ce_go_template_pipeline_step_%s{step=%q}", counter.name, metrics.Name), counter.help,
+				func() float64 { return value(pipeline.Metrics()[i]) })
+		}
+	}
+}
+
+func logPipelineMetrics(ctx context.Context, pipeline *cepipeline.Pipeline, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, metrics := range pipeline.Metrics() {
+				log.Print(metrics)
+			}
+		}
+	}
+}
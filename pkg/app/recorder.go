@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cerecorder"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+)
+
+// RecorderConfig writes received events to rotating files
+type RecorderConfig struct {
+	CeTemplate     string        `split_words:"true"`
+	CeSource       string        `split_words:"true"`
+	CeType         string        `split_words:"true"`
+	FilterTemplate string        `split_words:"true"`
+	RecordDir      string        `split_words:"true" default:"records"`
+	RecordPrefix   string        `split_words:"true" default:"events"`
+	MaxFileSize    int64         `split_words:"true" default:"104857600"`
+	MaxFileAge     time.Duration `split_words:"true" default:"1h"`
+	Compress       bool          `default:"false"`
+	Tee            bool          `default:"false"`
+	CePort         int           `split_words:"true" default:"8080"`
+	LimitConfig
+	DedupConfig
+	RuntimeConfig
+}
+
+// Info bla
+func (c *RecorderConfig) Info() string {
+	return fmt.Sprintf(`
+Configuration:
+====================================
+Verbose: %v
+Listening on port: %v
+Tee mode: %v
+Record dir: '%s'
+Record prefix: '%s'
+Max file size: %v bytes
+Max file age: %v
+Compress: %v
+Filter template: '%s'
+CeTemplate: '%v'
+cloudEvent source: '%s'
+cloudEvent type: '%s'`, c.Verbose, c.CePort, c.Tee, c.RecordDir, c.RecordPrefix, c.MaxFileSize, c.MaxFileAge, c.Compress, c.FilterTemplate, c.CeTemplate, c.CeSource, c.CeType)
+}
+
+func (c *RecorderConfig) handlers() (cehandler.CeFilter, cehandler.CeMapper, error) {
+	var filter cehandler.CeFilter
+	if c.FilterTemplate != "" {
+		predicate, err := cetransformer.NewCloudEventTransformer(c.FilterTemplate, "", "", c.Verbose)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create filter: %v", err)
+		}
+		filter = predicate
+	}
+	var mapper cehandler.CeMapper
+	if c.CeTemplate != "" {
+		transformer, err := cetransformer.NewCloudEventTransformer(c.CeTemplate, c.CeSource, c.CeType, c.Verbose)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create transformer: %v", err)
+		}
+		mapper = transformer
+	}
+	return filter, mapper, nil
+}
+
+// Validate bla
+func (c *RecorderConfig) Validate() error {
+	if err := c.LimitConfig.validate(); err != nil {
+		return err
+	}
+	if err := c.DedupConfig.validate(); err != nil {
+		return err
+	}
+	_, _, err := c.handlers()
+	return err
+}
+
+// Run bla
+func (c *RecorderConfig) Run(rt *Runtime) error {
+	filter, mapper, err := c.handlers()
+	if err != nil {
+		return err
+	}
+
+	writer, err := cerecorder.NewRotatingWriter(cerecorder.WriterConfig{Dir: c.RecordDir, Prefix: c.RecordPrefix,
+		MaxSize: c.MaxFileSize, MaxAge: c.MaxFileAge, Compress: c.Compress})
+	if err != nil {
+		return fmt.Errorf("failed to create writer: %v", err)
+	}
+	defer writer.Close()
+
+	rt.checkReceiver(c.CePort)
+
+	ceClient, err := rt.newReceiverClient(c.CePort, &c.LimitConfig, &c.DedupConfig)
+	if err != nil {
+		return err
+	}
+
+	recorder := cerecorder.NewCeRecorder(writer, filter, mapper, c.Tee, c.Verbose)
+	return ceClient.StartReceiver(rt.Context, recorder.HandleCe)
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/health"
+	"github.com/alitari/ce-go-template/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// checkTimeout timeout of the tcp checks of receiver and sink
+const checkTimeout = time.Second
+
+// flushTimeout time for flushing buffers after the in-flight events are drained
+var flushTimeout = 5 * time.Second
+
+// RuntimeConfig configuration of logging, the admin server, the shutdown and the encoding and overrides of outgoing events, shared by all modes
+type RuntimeConfig struct {
+	Verbose             bool          `default:"false"`
+	CeEncoding          string        `split_words:"true" default:"binary"`
+	CeOverrides         string        `envconfig:"K_CE_OVERRIDES"`
+	LogLevel            string        `split_words:"true" default:"info"`
+	LogFormat           string        `split_words:"true" default:"text"`
+	LogRedact           []string      `split_words:"true"`
+	AdminPort           int           `split_words:"true" default:"8090"`
+	SinkCheck           bool          `split_words:"true" default:"false"`
+	ShutdownGracePeriod time.Duration `split_words:"true" default:"20s"`
+}
+
+func (c *RuntimeConfig) runtimeConfig() *RuntimeConfig {
+	return c
+}
+
+// Runtime is passed to a running mode. The context is done when the mode has to shut down
+type Runtime struct {
+	Context   context.Context
+	Health    *health.Health
+	config    *RuntimeConfig
+	cancel    context.CancelFunc
+	templates *health.Flag
+	running   *health.Flag
+	admin     *http.Server
+}
+
+// newLogger the logger of the configuration. Payloads are only logged with VERBOSE and level debug
+func (c *RuntimeConfig) newLogger(out io.Writer) (*logging.Logger, error) {
+	level, err := logging.ParseLevel(c.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(out, c.LogFormat, level, c.LogRedact)
+}
+
+// encoding the content mode of sent events and replies
+func (c *RuntimeConfig) encoding() (binding.Encoding, error) {
+	return cehandler.ParseEncoding(c.CeEncoding)
+}
+
+// overrides the extensions of K_CE_OVERRIDES which are set on every sent event
+func (c *RuntimeConfig) overrides() (map[string]interface{}, error) {
+	return cehandler.ParseOverrides(c.CeOverrides)
+}
+
+func newRuntime(config *RuntimeConfig) *Runtime {
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := &Runtime{Context: ctx, Health: health.New(), config: config, cancel: cancel,
+		templates: health.NewFlag("templates are not parsed"), running: health.NewFlag("shutting down")}
+	rt.running.Set()
+	rt.Health.AddReadinessCheck("templates", rt.templates.Check)
+	rt.Health.AddReadinessCheck("shutdown", rt.running.Check)
+	return rt
+}
+
+// serve starts the admin server if the admin port is set
+func (rt *Runtime) serve() {
+	if rt.config.AdminPort <= 0 {
+		return
+	}
+	rt.Health.Handle("/loglevel", logging.LevelHandler(logging.Default()))
+	log.Printf("Admin server with /healthz, /readyz and /loglevel on port %d", rt.config.AdminPort)
+	rt.admin = rt.Health.Serve(rt.config.AdminPort)
+}
+
+// handleSignals shuts down on SIGTERM or SIGINT
+func (rt *Runtime) handleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case s := <-signals:
+			log.Printf("received signal %v, shutting down within %v", s, rt.config.ShutdownGracePeriod)
+			rt.shutdown()
+		case <-rt.Context.Done():
+		}
+	}()
+}
+
+// shutdown the mode isn't ready anymore and its context is done
+func (rt *Runtime) shutdown() {
+	rt.running.Unset()
+	rt.cancel()
+}
+
+// stop the admin server
+func (rt *Runtime) stop() {
+	rt.cancel()
+	if rt.admin != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		rt.admin.Shutdown(ctx)
+	}
+}
+
+// newReceiverClient a client receiving single events and batches on the port with deduplication and limits. On shutdown the active requests are drained within the grace period
+func (rt *Runtime) newReceiverClient(port int, limits *LimitConfig, dedup *DedupConfig) (cloudevents.Client, error) {
+	dedupOptions, err := dedup.protocolOptions()
+	if err != nil {
+		return nil, err
+	}
+	options := []cehttp.Option{cloudevents.WithPort(port), cehttp.WithShutdownTimeout(rt.config.ShutdownGracePeriod)}
+	options = append(append(options, dedupOptions...), limits.protocolOptions()...)
+	options = append(options, cehttp.WithMiddleware(cehandler.BatchReceiver))
+	httpProtocol, err := cloudevents.NewHTTP(options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create protocol: %v", err)
+	}
+	client, err := rt.newClient(httpProtocol)
+	if err != nil {
+		return nil, err
+	}
+	client, err = limits.limitClient(client)
+	if err != nil {
+		return nil, err
+	}
+	return rt.withOverrides(client)
+}
+
+// newClient a client sending and replying events with CE_ENCODING
+func (rt *Runtime) newClient(httpProtocol *cehttp.Protocol) (cloudevents.Client, error) {
+	encoding, err := rt.config.encoding()
+	if err != nil {
+		return nil, err
+	}
+	client, err := cloudevents.NewClient(httpProtocol)
+	if err != nil {
+		return nil, err
+	}
+	return cehandler.NewEncodingClient(client, encoding), nil
+}
+
+// withOverrides the client sets the extensions of K_CE_OVERRIDES on every sent event. It has to wrap a batching client, which doesn't send with its inner client
+func (rt *Runtime) withOverrides(client cloudevents.Client) (cloudevents.Client, error) {
+	extensions, err := rt.config.overrides()
+	if err != nil {
+		return nil, err
+	}
+	return cehandler.NewOverridesClient(client, extensions), nil
+}
+
+// checkReceiver the mode is ready when the receiver accepts connections
+func (rt *Runtime) checkReceiver(port int) {
+	rt.Health.AddReadinessCheck("receiver", health.TCPCheck(net.JoinHostPort("localhost", strconv.Itoa(port)), checkTimeout))
+}
+
+// checkSink the mode is ready when the sink accepts connections, only if SINK_CHECK is enabled
+func (rt *Runtime) checkSink(sink string) error {
+	if !rt.config.SinkCheck || sink == "" {
+		return nil
+	}
+	check, err := health.SinkCheck(sink, checkTimeout)
+	if err != nil {
+		return err
+	}
+	rt.Health.AddReadinessCheck("sink", check)
+	return nil
+}
+
+// checkCircuitBreaker the mode is not ready while a circuit is open
+func (rt *Runtime) checkCircuitBreaker(breaker *cehttpclienttransformer.CircuitBreaker) {
+	rt.Health.AddReadinessCheck("circuitbreaker", func() error {
+		if open := breaker.OpenTargets(); len(open) > 0 {
+			return fmt.Errorf("circuit breaker is open for %s", strings.Join(open, ", "))
+		}
+		return nil
+	})
+}
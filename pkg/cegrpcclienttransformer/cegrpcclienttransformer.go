@@ -0,0 +1,168 @@
+package cegrpcclienttransformer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/logging"
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Config configuration of the gRPC client transformer
+type Config struct {
+	RequestTemplate  string
+	ResponseTemplate string
+	Timeout          time.Duration
+	Metadata         map[string]string
+	// Source and Type of the result event, default is the source and type of the incoming event
+	Source string
+	Type   string
+	Debug  bool
+}
+
+// CeGRPCClientTransformer calls a unary gRPC method for every event
+type CeGRPCClientTransformer struct {
+	config          Config
+	conn            *grpc.ClientConn
+	method          protoreflect.MethodDescriptor
+	fullMethod      string
+	grpcTransformer *transformer.Transformer
+	ceTransformer   *transformer.Transformer
+}
+
+// NewCeGRPCClientTransformer the request template renders the request message as json, the response message is available as `grpcresponse` in the response template
+func NewCeGRPCClientTransformer(conn *grpc.ClientConn, method protoreflect.MethodDescriptor, config Config) (*CeGRPCClientTransformer, error) {
+	cgt := new(CeGRPCClientTransformer)
+	cgt.config = config
+	cgt.conn = conn
+	cgt.method = method
+	cgt.fullMethod = fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	grpcTransformer, err := transformer.NewTransformer(config.RequestTemplate, nil, config.Debug)
+	if err != nil {
+		return nil, err
+	}
+	cgt.grpcTransformer = grpcTransformer
+	ceTransformer, err := transformer.NewTransformer(config.ResponseTemplate, nil, config.Debug)
+	if err != nil {
+		return nil, err
+	}
+	cgt.ceTransformer = ceTransformer
+	return cgt, nil
+}
+
+func (ct *CeGRPCClientTransformer) transformEventToBytes(sourceEvent *cloudevents.Event) ([]byte, error) {
+	inputEventData := cetransformer.EventToMap(sourceEvent)
+	requestJSON, err := ct.grpcTransformer.TransformInputToBytes(inputEventData)
+	if err != nil {
+		return nil, err
+	}
+	request := dynamicpb.NewMessage(ct.method.Input())
+	if err := protojson.Unmarshal(requestJSON, request); err != nil {
+		return nil, fmt.Errorf("can't create request message '%s' from '%s': %v", ct.method.Input().FullName(), requestJSON, err)
+	}
+	response := dynamicpb.NewMessage(ct.method.Output())
+	ctx, cancel := context.WithTimeout(context.Background(), ct.config.Timeout)
+	defer cancel()
+	if len(ct.config.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(ct.config.Metadata))
+	}
+	if ct.config.Debug {
+		logging.DebugPayload(fmt.Sprintf("invoking gRPC method %s", ct.fullMethod), requestJSON)
+	}
+	if err := ct.conn.Invoke(ctx, ct.fullMethod, request, response, grpc.ForceCodec(Codec{})); err != nil {
+		return nil, err
+	}
+	respData, err := MessageToMap(response)
+	if err != nil {
+		return nil, err
+	}
+	input := map[string]interface{}{}
+	input["inputce"] = inputEventData
+	input["grpcresponse"] = respData
+	return ct.ceTransformer.TransformInputToBytes(input)
+}
+
+// TransformEvent bla
+func (ct *CeGRPCClientTransformer) TransformEvent(sourceEvent *cloudevents.Event) (*cloudevents.Event, error) {
+	eventBytes, err := ct.transformEventToBytes(sourceEvent)
+	if err != nil {
+		return nil, err
+	}
+	result, err := cetransformer.TransformBytesToEvent(eventBytes, sourceEvent.Context.Clone())
+	if err != nil {
+		return nil, err
+	}
+	if ct.config.Source != "" {
+		result.SetSource(ct.config.Source)
+	}
+	if ct.config.Type != "" {
+		result.SetType(ct.config.Type)
+	}
+	if sourceEvent.ID() != "" {
+		result.SetID(cetransformer.DeriveID(sourceEvent, result.Type()))
+	}
+	return result, nil
+}
+
+// PredicateEvent bla
+func (ct *CeGRPCClientTransformer) PredicateEvent(sourceEvent *cloudevents.Event) (bool, error) {
+	booleanBytes, err := ct.transformEventToBytes(sourceEvent)
+	if err != nil {
+		return false, err
+	}
+	return string(booleanBytes) == "true", nil
+}
+
+// MessageToMap converts a proto message to a map using the proto json mapping
+func MessageToMap(message proto.Message) (map[string]interface{}, error) {
+	messageJSON, err := protojson.MarshalOptions{UseProtoNames: false, EmitUnpopulated: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(messageJSON, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Codec gRPC codec for dynamic proto messages
+type Codec struct{}
+
+// Marshal bla
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("can't marshal %T, not a proto message", v)
+	}
+	return proto.Marshal(message)
+}
+
+// Unmarshal bla
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("can't unmarshal %T, not a proto message", v)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+// Name bla
+func (Codec) Name() string {
+	return "proto"
+}
+
+// String bla
+func (Codec) String() string {
+	return "proto"
+}
@@ -0,0 +1,186 @@
+package cegrpcclienttransformer
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func greeterFileSet() *descriptorpb.FileDescriptorSet {
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), JsonName: proto.String(name),
+			Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+	}
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("greeter.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("HelloRequest"), Field: []*descriptorpb.FieldDescriptorProto{field("name", 1)}},
+			{Name: proto.String("HelloReply"), Field: []*descriptorpb.FieldDescriptorProto{field("message", 1)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{Name: proto.String("SayHello"), InputType: proto.String(".test.HelloRequest"), OutputType: proto.String(".test.HelloReply")}},
+		}},
+	}}}
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	content, err := proto.Marshal(greeterFileSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "grpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "greeter.protoset")
+	if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// startGreeter serves test.Greeter/SayHello without generated code
+func startGreeter(t *testing.T, method protoreflect.MethodDescriptor) (*grpc.Server, string) {
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		request := dynamicpb.NewMessage(method.Input())
+		if err := stream.RecvMsg(request); err != nil {
+			return err
+		}
+		name := request.Get(method.Input().Fields().ByName("name")).String()
+		if name == "" {
+			return status.Error(codes.InvalidArgument, "name is missing")
+		}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		reply := dynamicpb.NewMessage(method.Output())
+		reply.Set(method.Output().Fields().ByName("message"), protoreflect.ValueOfString("Hello "+name+" from "+md.Get("tenant")[0]))
+		return stream.SendMsg(reply)
+	}
+	server := grpc.NewServer(grpc.CustomCodec(Codec{}), grpc.UnknownServiceHandler(handler))
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	return server, listener.Addr().String()
+}
+
+func TestTransformEvent(t *testing.T) {
+	filename := writeDescriptorSet(t)
+	defer os.RemoveAll(filepath.Dir(filename))
+	method, err := MethodFromDescriptorSetFile(filename, "test.Greeter/SayHello")
+	if err != nil {
+		t.Fatalf("MethodFromDescriptorSetFile() error = %v", err)
+	}
+	server, address := startGreeter(t, method)
+	defer server.Stop()
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name                  string
+		givenRequestTemplate  string
+		givenResponseTemplate string
+		givenSource           string
+		givenType             string
+		whenIncomingEvent     cloudevents.Event
+		thenWantOutgoingEvent cloudevents.Event
+		thenWantErr           bool
+	}{
+		{name: "say hello",
+			givenRequestTemplate:  `{ "name": {{ .data.name | quote }} }`,
+			givenResponseTemplate: `{ "greeting": {{ .grpcresponse.message | quote }}, "name": {{ .inputce.data.name | quote }} }`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "name": "Alex" }`),
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "greeting": "Hello Alex from a", "name": "Alex" }`),
+		},
+		{name: "source and type",
+			givenRequestTemplate:  `{ "name": {{ .data.name | quote }} }`,
+			givenResponseTemplate: `{ "greeting": {{ .grpcresponse.message | quote }} }`,
+			givenSource:           "greeter",
+			givenType:             "greeted",
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "name": "Alex" }`),
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "greeting": "Hello Alex from a" }`, "greeter", "greeted"),
+		},
+		{name: "unknown field",
+			givenRequestTemplate:  `{ "firstname": {{ .data.name | quote }} }`,
+			givenResponseTemplate: `{}`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "name": "Alex" }`),
+			thenWantErr:           true,
+		},
+		{name: "status error",
+			givenRequestTemplate:  `{}`,
+			givenResponseTemplate: `{}`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{}`),
+			thenWantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct, err := NewCeGRPCClientTransformer(conn, method, Config{RequestTemplate: tt.givenRequestTemplate, ResponseTemplate: tt.givenResponseTemplate,
+				Timeout: time.Second, Metadata: map[string]string{"tenant": "a"}, Source: tt.givenSource, Type: tt.givenType, Debug: true})
+			if err != nil {
+				t.Errorf("NewCeGRPCClientTransformer() error = %v", err)
+				return
+			}
+			outgoingCe, err := ct.TransformEvent(&tt.whenIncomingEvent)
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("CeGRPCClientTransformer.TransformEvent error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if err == nil {
+				cetransformer.CompareEvents(t, "CeGRPCClientTransformer.TransformEvent", *outgoingCe, tt.thenWantOutgoingEvent)
+				if err := outgoingCe.Validate(); err != nil {
+					t.Errorf("CeGRPCClientTransformer.TransformEvent invalid event: %v", err)
+				}
+				if wantID := cetransformer.DeriveID(&tt.whenIncomingEvent, outgoingCe.Type()); outgoingCe.ID() != wantID {
+					t.Errorf("CeGRPCClientTransformer.TransformEvent id = %s, want derived id %s", outgoingCe.ID(), wantID)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMethodName(t *testing.T) {
+	tests := []struct {
+		whenMethod      string
+		thenWantService string
+		thenWantMethod  string
+		thenWantErr     bool
+	}{
+		{whenMethod: "test.Greeter/SayHello", thenWantService: "test.Greeter", thenWantMethod: "SayHello"},
+		{whenMethod: "/test.Greeter/SayHello", thenWantService: "test.Greeter", thenWantMethod: "SayHello"},
+		{whenMethod: "test.Greeter.SayHello", thenWantService: "test.Greeter", thenWantMethod: "SayHello"},
+		{whenMethod: "SayHello", thenWantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.whenMethod, func(t *testing.T) {
+			service, method, err := ParseMethodName(tt.whenMethod)
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("ParseMethodName() error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if service != tt.thenWantService || method != tt.thenWantMethod {
+				t.Errorf("ParseMethodName() = %s, %s, want %s, %s", service, method, tt.thenWantService, tt.thenWantMethod)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cegrpcclienttransformer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ParseMethodName splits "package.Service/Method" or "package.Service.Method" in service and method name
+func ParseMethodName(fullMethod string) (string, string, error) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	i := strings.LastIndex(fullMethod, "/")
+	if i < 0 {
+		i = strings.LastIndex(fullMethod, ".")
+	}
+	if i <= 0 || i == len(fullMethod)-1 {
+		return "", "", fmt.Errorf("invalid method name '%s', expected 'package.Service/Method'", fullMethod)
+	}
+	return fullMethod[:i], fullMethod[i+1:], nil
+}
+
+// MethodFromDescriptorSetFile finds the method descriptor in a file descriptor set created with `protoc --include_imports --descriptor_set_out`
+func MethodFromDescriptorSetFile(filename, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, fileSet); err != nil {
+		return nil, fmt.Errorf("can't parse descriptor set file '%s': %v", filename, err)
+	}
+	return methodFromFileSet(fileSet, fullMethod)
+}
+
+// MethodFromReflection requests the method descriptor from the server reflection service
+func MethodFromReflection(ctx context.Context, conn *grpc.ClientConn, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	serviceName, _, err := ParseMethodName(fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection not available: %v", err)
+	}
+	defer stream.CloseSend()
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	request := &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName}}
+	pending := []*rpb.ServerReflectionRequest{request}
+	for len(pending) > 0 {
+		request, pending = pending[0], pending[1:]
+		if err := stream.Send(request); err != nil {
+			return nil, fmt.Errorf("server reflection request failed: %v", err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("server reflection response failed: %v", err)
+		}
+		if errResp := response.GetErrorResponse(); errResp != nil {
+			return nil, fmt.Errorf("server reflection error for '%s': %s", serviceName, errResp.GetErrorMessage())
+		}
+		for _, fileBytes := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(fileBytes, file); err != nil {
+				return nil, fmt.Errorf("invalid file descriptor from server reflection: %v", err)
+			}
+			files[file.GetName()] = file
+		}
+		// request the dependencies the server didn't send
+		for _, file := range files {
+			for _, dep := range file.GetDependency() {
+				if _, ok := files[dep]; !ok && !isPending(pending, dep) {
+					pending = append(pending, &rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep}})
+				}
+			}
+		}
+	}
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		fileSet.File = append(fileSet.File, file)
+	}
+	return methodFromFileSet(fileSet, fullMethod)
+}
+
+func isPending(pending []*rpb.ServerReflectionRequest, filename string) bool {
+	for _, p := range pending {
+		if p.GetFileByFilename() == filename {
+			return true
+		}
+	}
+	return false
+}
+
+func methodFromFileSet(fileSet *descriptorpb.FileDescriptorSet, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, err := ParseMethodName(fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors: %v", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service '%s' not found: %v", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("method '%s' not found in service '%s'", methodName, serviceName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("method '%s' is not unary", fullMethod)
+	}
+	return method, nil
+}
@@ -0,0 +1,94 @@
+package cehandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// BatchReceiver a middleware which passes each event of a batch request as structured request to the next handler. The batch fails with the status of the first failed event, the following events are not processed. The replied events are returned as batch
+func BatchReceiver(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isBatch(r.Header.Get("Content-Type")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var batch []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, "can't parse batch: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		replies := []*cloudevents.Event{}
+		for i, event := range batch {
+			req := r.Clone(r.Context())
+			req.Body = ioutil.NopCloser(bytes.NewReader(event))
+			req.ContentLength = int64(len(event))
+			req.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsJSON)
+			rw := &batchResponseWriter{header: http.Header{}, status: http.StatusOK}
+			next.ServeHTTP(rw, req)
+			if rw.status >= 300 {
+				http.Error(w, fmt.Sprintf("event %d of batch failed: %s", i, rw.body.String()), rw.status)
+				return
+			}
+			reply, err := rw.reply(r.Context())
+			if err != nil {
+				http.Error(w, fmt.Sprintf("can't read reply of event %d of batch: %v", i, err), http.StatusInternalServerError)
+				return
+			}
+			if reply != nil {
+				replies = append(replies, reply)
+			}
+		}
+		if len(replies) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		body, err := json.Marshal(replies)
+		if err != nil {
+			http.Error(w, "can't marshal replies of batch: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", cloudevents.ApplicationCloudEventsBatchJSON)
+		w.Write(body)
+	})
+}
+
+func isBatch(contentType string) bool {
+	return strings.HasPrefix(contentType, cloudevents.ApplicationCloudEventsBatchJSON)
+}
+
+// batchResponseWriter keeps the response of an event of a batch
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *batchResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *batchResponseWriter) Write(b []byte) (int, error) {
+	return rw.body.Write(b)
+}
+
+func (rw *batchResponseWriter) WriteHeader(status int) {
+	rw.status = status
+}
+
+// reply the event of the response, nil if the response has no event
+func (rw *batchResponseWriter) reply(ctx context.Context) (*cloudevents.Event, error) {
+	message := cehttp.NewMessage(rw.header, ioutil.NopCloser(&rw.body))
+	if message.ReadEncoding() == binding.EncodingUnknown {
+		return nil, nil
+	}
+	return binding.ToEvent(ctx, message)
+}
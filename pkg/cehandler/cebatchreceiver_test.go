@@ -0,0 +1,92 @@
+package cehandler
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestBatchReceiver(t *testing.T) {
+	batch := []cloudevents.Event{
+		cetransformer.NewEventWithJSONStringData(`{"foo": "1"}`, "source", "type", "1"),
+		cetransformer.NewEventWithJSONStringData(`{"foo": "2"}`, "source", "type", "2"),
+		cetransformer.NewEventWithJSONStringData(`{"foo": "3"}`, "source", "type", "3"),
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name            string
+		givenFailingID  string
+		givenReply      bool
+		whenContentType string
+		whenBody        string
+		thenStatus      int
+		thenIDs         []string
+		thenReplyIDs    []string
+	}{
+		{name: "batch", whenContentType: cloudevents.ApplicationCloudEventsBatchJSON, whenBody: string(body), thenStatus: http.StatusAccepted, thenIDs: []string{"1", "2", "3"}},
+		{name: "reply", givenReply: true, whenContentType: cloudevents.ApplicationCloudEventsBatchJSON, whenBody: string(body), thenStatus: http.StatusOK, thenIDs: []string{"1", "2", "3"},
+			thenReplyIDs: []string{"reply-1", "reply-3"}},
+		{name: "failed event", givenFailingID: "2", whenContentType: cloudevents.ApplicationCloudEventsBatchJSON, whenBody: string(body), thenStatus: http.StatusServiceUnavailable, thenIDs: []string{"1", "2"}},
+		{name: "invalid batch", whenContentType: cloudevents.ApplicationCloudEventsBatchJSON, whenBody: `{"id": "1"}`, thenStatus: http.StatusBadRequest, thenIDs: []string{}},
+		{name: "single event", whenContentType: cloudevents.ApplicationCloudEventsJSON, whenBody: `{"specversion": "1.0", "id": "1", "source": "source", "type": "type"}`, thenStatus: http.StatusOK, thenIDs: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				event, err := binding.ToEvent(context.Background(), cehttp.NewMessageFromHttpRequest(r))
+				if err != nil {
+					t.Fatalf("can't read event: %v", err)
+				}
+				ids = append(ids, event.ID())
+				if event.ID() == tt.givenFailingID {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+				if tt.givenReply && event.ID() != "2" {
+					reply := cetransformer.NewEventWithJSONStringData(`{"reply": true}`, "source", "reply", "reply-"+event.ID())
+					cehttp.WriteResponseWriter(context.Background(), binding.ToMessage(&reply), http.StatusOK, w)
+				}
+			})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.whenBody))
+			req.Header.Set("Content-Type", tt.whenContentType)
+			BatchReceiver(next).ServeHTTP(w, req)
+			if w.Code != tt.thenStatus {
+				respBody, _ := ioutil.ReadAll(w.Body)
+				t.Errorf("BatchReceiver status = %d, want %d: %s", w.Code, tt.thenStatus, respBody)
+			}
+			if !reflect.DeepEqual(ids, tt.thenIDs) {
+				t.Errorf("BatchReceiver processed events %v, want %v", ids, tt.thenIDs)
+			}
+			if tt.thenReplyIDs != nil {
+				if contentType := w.Header().Get("Content-Type"); contentType != cloudevents.ApplicationCloudEventsBatchJSON {
+					t.Errorf("BatchReceiver reply content type = %s, want %s", contentType, cloudevents.ApplicationCloudEventsBatchJSON)
+				}
+				var replies []cloudevents.Event
+				if err := json.NewDecoder(w.Body).Decode(&replies); err != nil {
+					t.Fatalf("can't parse replies: %v", err)
+				}
+				replyIDs := []string{}
+				for _, reply := range replies {
+					replyIDs = append(replyIDs, reply.ID())
+				}
+				if !reflect.DeepEqual(replyIDs, tt.thenReplyIDs) {
+					t.Errorf("BatchReceiver replied events %v, want %v", replyIDs, tt.thenReplyIDs)
+				}
+			}
+		})
+	}
+}
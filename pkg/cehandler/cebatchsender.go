@@ -0,0 +1,222 @@
+package cehandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// maxQueuedBatches number of batches waiting for the flusher, Send blocks if the queue is full
+const maxQueuedBatches = 8
+
+// CeBatchSender a client which groups sent events and sends them to the sink as one batch request. Send returns before the event is delivered,
+// the batches are sent in order by a background flusher, failed batches are retried and logged
+type CeBatchSender struct {
+	cloudevents.Client
+	httpClient *http.Client
+	sink       string
+	maxEvents  int
+	maxDelay   time.Duration
+	maxRetries int
+	minBackoff time.Duration
+	mu         sync.Mutex
+	batch      []cloudevents.Event
+	timer      *time.Timer
+	queue      chan []cloudevents.Event
+	flushed    chan struct{}
+	unbatched  int32
+	closed     bool
+	stopped    chan struct{}
+	stop       sync.Once
+}
+
+// NewBatchSender a batch is sent when it has maxEvents events or its first event is maxDelay old. Events are sent individually with the client if the sink responds to a batch with status 415
+func NewBatchSender(client cloudevents.Client, sink string, maxEvents int, maxDelay, timeout time.Duration) *CeBatchSender {
+	bs := &CeBatchSender{Client: client, httpClient: &http.Client{Timeout: timeout}, sink: sink, maxEvents: maxEvents, maxDelay: maxDelay,
+		queue: make(chan []cloudevents.Event, maxQueuedBatches), flushed: make(chan struct{}), stopped: make(chan struct{})}
+	go bs.flusher()
+	return bs
+}
+
+// WithRetries a batch which failed with a network error, status 5xx, 408 or 429 is sent again up to maxRetries times, the backoff starts with minBackoff and is doubled for every retry
+func (bs *CeBatchSender) WithRetries(maxRetries int, minBackoff time.Duration) *CeBatchSender {
+	bs.maxRetries = maxRetries
+	bs.minBackoff = minBackoff
+	return bs
+}
+
+// Send adds the event to the batch, a full batch is queued for the flusher and Send blocks while the queue is full.
+// After the sink rejected a batch or after Close the event is sent individually
+func (bs *CeBatchSender) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	bs.mu.Lock()
+	if atomic.LoadInt32(&bs.unbatched) == 1 || bs.closed {
+		bs.mu.Unlock()
+		return bs.Client.Send(ctx, event)
+	}
+	bs.batch = append(bs.batch, event)
+	if len(bs.batch) == 1 {
+		bs.timer = time.AfterFunc(bs.maxDelay, bs.flushAsync)
+	}
+	var err error
+	if len(bs.batch) >= bs.maxEvents {
+		err = bs.enqueue(ctx.Done())
+	}
+	bs.mu.Unlock()
+	return err
+}
+
+// Close queues the pending batch and waits for the flusher until the context is done, then the pending retries are stopped
+func (bs *CeBatchSender) Close(ctx context.Context) error {
+	go func() {
+		bs.mu.Lock()
+		defer bs.mu.Unlock()
+		if !bs.closed {
+			if err := bs.enqueue(nil); err != nil {
+				logging.Errorf("%v", err)
+			}
+			bs.closed = true
+			close(bs.queue)
+		}
+	}()
+	select {
+	case <-bs.flushed:
+		return nil
+	case <-ctx.Done():
+		bs.stop.Do(func() { close(bs.stopped) })
+		return fmt.Errorf("pending batches not sent: %v", ctx.Err())
+	}
+}
+
+// take the pending batch, the lock must be held
+func (bs *CeBatchSender) take() []cloudevents.Event {
+	if bs.timer != nil {
+		bs.timer.Stop()
+		bs.timer = nil
+	}
+	batch := bs.batch
+	bs.batch = nil
+	return batch
+}
+
+// enqueue the pending batch for the flusher, blocks while the queue is full until done is closed or the retries are stopped. The lock must be held
+func (bs *CeBatchSender) enqueue(done <-chan struct{}) error {
+	batch := bs.take()
+	if len(batch) == 0 {
+		return nil
+	}
+	select {
+	case bs.queue <- batch:
+		return nil
+	case <-done:
+	case <-bs.stopped:
+	}
+	return fmt.Errorf("batch of %d events not sent, the queue is full", len(batch))
+}
+
+func (bs *CeBatchSender) flushAsync() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.closed {
+		return
+	}
+	if err := bs.enqueue(nil); err != nil {
+		logging.Errorf("%v", err)
+	}
+}
+
+// flusher sends the queued batches until the queue is closed, after Close stopped the retries the queued batches are dropped
+func (bs *CeBatchSender) flusher() {
+	defer close(bs.flushed)
+	for batch := range bs.queue {
+		select {
+		case <-bs.stopped:
+			logging.Errorf("batch of %d events not sent, retries stopped", len(batch))
+		default:
+			bs.flush(batch)
+		}
+	}
+}
+
+func (bs *CeBatchSender) flush(batch []cloudevents.Event) {
+	if len(batch) == 0 {
+		return
+	}
+	backoff := bs.minBackoff
+	for retry := 0; ; retry++ {
+		status, err := bs.sendBatch(batch)
+		switch {
+		case err != nil:
+		case status < 300:
+			logging.Debugf("batch of %d events sent, status %d", len(batch), status)
+			return
+		case status == http.StatusUnsupportedMediaType:
+			logging.Warnf("sink responded with status %d to a batch, sending events individually", status)
+			atomic.StoreInt32(&bs.unbatched, 1)
+			bs.sendIndividually(batch)
+			return
+		case !retryable(status):
+			logging.Errorf("batch of %d events not sent, sink responded with status %d", len(batch), status)
+			return
+		default:
+			err = fmt.Errorf("sink responded with status %d", status)
+		}
+		if retry >= bs.maxRetries {
+			logging.Errorf("batch of %d events not sent after %d retries: %v", len(batch), retry, err)
+			return
+		}
+		logging.Warnf("batch of %d events not sent, retry in %v: %v", len(batch), backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-bs.stopped:
+			logging.Errorf("batch of %d events not sent, retries stopped: %v", len(batch), err)
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// retryable a batch with this status may succeed later
+func retryable(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
+
+func (bs *CeBatchSender) sendBatch(batch []cloudevents.Event) (int, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return 0, fmt.Errorf("can't marshal batch: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, bs.sink, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", cloudevents.ApplicationCloudEventsBatchJSON)
+	resp, err := bs.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (bs *CeBatchSender) sendIndividually(batch []cloudevents.Event) {
+	for _, event := range batch {
+		ctx, cancel := context.WithTimeout(context.Background(), bs.httpClient.Timeout)
+		result := bs.Client.Send(cloudevents.ContextWithTarget(ctx, bs.sink), event)
+		cancel()
+		if !cloudevents.IsACK(result) {
+			logging.ForEvent(event).Errorf("event of batch not sent: %v", result)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cehandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestCeBatchSender_Send(t *testing.T) {
+	tests := []struct {
+		name            string
+		givenMaxEvents  int
+		givenSinkStatus int
+		givenFailures   int
+		whenEvents      int
+		thenBatches     []int
+		thenIndividual  bool
+	}{
+		{name: "full batch and flush on close", givenMaxEvents: 2, givenSinkStatus: http.StatusAccepted, whenEvents: 3, thenBatches: []int{2, 1}},
+		{name: "single batch", givenMaxEvents: 5, givenSinkStatus: http.StatusOK, whenEvents: 5, thenBatches: []int{5}},
+		{name: "failed batch retried", givenMaxEvents: 2, givenSinkStatus: http.StatusAccepted, givenFailures: 1, whenEvents: 2, thenBatches: []int{2, 2}},
+		{name: "retries exhausted", givenMaxEvents: 2, givenSinkStatus: http.StatusAccepted, givenFailures: 5, whenEvents: 2, thenBatches: []int{2, 2, 2}},
+		{name: "permanent error", givenMaxEvents: 2, givenSinkStatus: http.StatusBadRequest, whenEvents: 2, thenBatches: []int{2}},
+		{name: "batch rejected", givenMaxEvents: 2, givenSinkStatus: http.StatusUnsupportedMediaType, whenEvents: 2, thenBatches: []int{2}, thenIndividual: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			batches := []int{}
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != cloudevents.ApplicationCloudEventsBatchJSON {
+					t.Errorf("batch content type = '%s'", r.Header.Get("Content-Type"))
+				}
+				var batch []cloudevents.Event
+				if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+					t.Errorf("can't decode batch: %v", err)
+				}
+				mu.Lock()
+				batches = append(batches, len(batch))
+				failed := len(batches) <= tt.givenFailures
+				mu.Unlock()
+				if failed {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(tt.givenSinkStatus)
+			}))
+			defer sink.Close()
+			ceClient := &cetransformer.CeClientMock{T: t, WantSend: tt.thenIndividual, WantSendEvent: outgoingEvent}
+			batchSender := NewBatchSender(ceClient, sink.URL, tt.givenMaxEvents, time.Hour, time.Second).WithRetries(2, time.Millisecond)
+			for i := 0; i < tt.whenEvents; i++ {
+				if result := batchSender.Send(context.Background(), outgoingEvent); result != nil {
+					t.Errorf("CeBatchSender.Send() result = %v", result)
+				}
+			}
+			if err := batchSender.Close(context.Background()); err != nil {
+				t.Errorf("CeBatchSender.Close() error = %v", err)
+			}
+			if !reflect.DeepEqual(batches, tt.thenBatches) {
+				t.Errorf("CeBatchSender batches = %v, want %v", batches, tt.thenBatches)
+			}
+		})
+	}
+}
+
+func TestCeBatchSender_CloseStopsRetries(t *testing.T) {
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sink.Close()
+	batchSender := NewBatchSender(&cetransformer.CeClientMock{T: t}, sink.URL, 100, time.Hour, time.Second).WithRetries(10, time.Hour)
+	batchSender.Send(context.Background(), outgoingEvent)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := batchSender.Close(ctx); err == nil {
+		t.Error("CeBatchSender.Close() with pending retry error = nil")
+	}
+	select {
+	case <-batchSender.flushed:
+	case <-time.After(time.Second):
+		t.Error("CeBatchSender retry not stopped after Close")
+	}
+}
+
+func TestCeBatchSender_SendDuringRetry(t *testing.T) {
+	received := make(chan struct{}, 1)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sink.Close()
+	batchSender := NewBatchSender(&cetransformer.CeClientMock{T: t}, sink.URL, 1, time.Hour, time.Second).WithRetries(10, time.Hour)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		batchSender.Close(ctx)
+	}()
+	sent := make(chan struct{})
+	go func() {
+		batchSender.Send(context.Background(), outgoingEvent)
+		<-received
+		batchSender.Send(context.Background(), outgoingEvent)
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Error("CeBatchSender.Send() blocked by a pending retry")
+	}
+}
+
+func TestCeBatchSender_SendQueueFull(t *testing.T) {
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sink.Close()
+	batchSender := NewBatchSender(&cetransformer.CeClientMock{T: t}, sink.URL, 1, time.Hour, time.Second).WithRetries(10, time.Hour)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		batchSender.Close(ctx)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var result error
+	for i := 0; i <= maxQueuedBatches+1 && result == nil; i++ {
+		result = batchSender.Send(ctx, outgoingEvent)
+	}
+	if result == nil {
+		t.Error("CeBatchSender.Send() with full queue, want error")
+	}
+}
+
+func TestCeBatchSender_maxDelay(t *testing.T) {
+	sent := make(chan struct{}, 1)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent <- struct{}{}
+	}))
+	defer sink.Close()
+	batchSender := NewBatchSender(&cetransformer.CeClientMock{T: t}, sink.URL, 100, 10*time.Millisecond, time.Second)
+	batchSender.Send(context.Background(), outgoingEvent)
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Error("CeBatchSender batch not sent after max delay")
+	}
+}
@@ -0,0 +1,53 @@
+package cehandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ParseEncoding the http content mode 'binary' or 'structured'
+func ParseEncoding(name string) (binding.Encoding, error) {
+	switch strings.ToLower(name) {
+	case "binary":
+		return binding.EncodingBinary, nil
+	case "structured":
+		return binding.EncodingStructured, nil
+	}
+	return binding.EncodingUnknown, fmt.Errorf("unknown encoding '%s', use 'binary' or 'structured'", name)
+}
+
+// encodingClient sends events and replies to received events in a content mode
+type encodingClient struct {
+	cloudevents.Client
+	encoding binding.Encoding
+}
+
+// NewEncodingClient the client sends and replies events in the content mode of the encoding
+func NewEncodingClient(client cloudevents.Client, encoding binding.Encoding) cloudevents.Client {
+	return &encodingClient{Client: client, encoding: encoding}
+}
+
+func (c *encodingClient) withEncoding(ctx context.Context) context.Context {
+	if c.encoding == binding.EncodingStructured {
+		return binding.WithForceStructured(ctx)
+	}
+	return binding.WithForceBinary(ctx)
+}
+
+func (c *encodingClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	return c.Client.Send(c.withEncoding(ctx), event)
+}
+
+func (c *encodingClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	return c.Client.Request(c.withEncoding(ctx), event)
+}
+
+// StartReceiver the replies are written with the context of the receiver
+func (c *encodingClient) StartReceiver(ctx context.Context, fn interface{}) error {
+	return c.Client.StartReceiver(c.withEncoding(ctx), fn)
+}
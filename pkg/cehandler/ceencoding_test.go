@@ -0,0 +1,103 @@
+package cehandler
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		whenName     string
+		thenEncoding binding.Encoding
+		thenError    bool
+	}{
+		{name: "binary", whenName: "binary", thenEncoding: binding.EncodingBinary},
+		{name: "structured", whenName: "Structured", thenEncoding: binding.EncodingStructured},
+		{name: "unknown", whenName: "batch", thenEncoding: binding.EncodingUnknown, thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := ParseEncoding(tt.whenName)
+			if encoding != tt.thenEncoding || (err != nil) != tt.thenError {
+				t.Errorf("ParseEncoding() = %v, %v, want %v, error %v", encoding, err, tt.thenEncoding, tt.thenError)
+			}
+		})
+	}
+}
+
+var encodingTests = []struct {
+	name            string
+	givenEncoding   binding.Encoding
+	thenContentType string
+}{
+	{name: "binary", givenEncoding: binding.EncodingBinary, thenContentType: "application/json"},
+	{name: "structured", givenEncoding: binding.EncodingStructured, thenContentType: cloudevents.ApplicationCloudEventsJSON},
+}
+
+func TestEncodingClient_Send(t *testing.T) {
+	for _, tt := range encodingTests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType := make(chan string, 1)
+			sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType <- r.Header.Get("Content-Type")
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer sink.Close()
+			client, err := cloudevents.NewDefaultClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := cetransformer.NewEventWithJSONStringData(`{"foo": "foo"}`)
+			result := NewEncodingClient(client, tt.givenEncoding).Send(cloudevents.ContextWithTarget(context.Background(), sink.URL), event)
+			if !cloudevents.IsACK(result) {
+				t.Fatalf("encodingClient.Send() result = %v", result)
+			}
+			if actual := <-contentType; actual != tt.thenContentType {
+				t.Errorf("encodingClient.Send() content type = '%s', want '%s'", actual, tt.thenContentType)
+			}
+		})
+	}
+}
+
+func TestEncodingClient_reply(t *testing.T) {
+	for _, tt := range encodingTests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpProtocol, err := cloudevents.NewHTTP(cehttp.WithListener(listener))
+			if err != nil {
+				t.Fatal(err)
+			}
+			client, err := cloudevents.NewClient(httpProtocol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go NewEncodingClient(client, tt.givenEncoding).StartReceiver(ctx, func(event cloudevents.Event) *cloudevents.Event { return &event })
+			time.Sleep(50 * time.Millisecond)
+			body := []byte(`{"specversion": "1.0", "id": "1", "source": "source", "type": "type", "datacontenttype": "application/json", "data": {"foo": "foo"}}`)
+			resp, err := http.Post("http://"+listener.Addr().String(), cloudevents.ApplicationCloudEventsJSON, bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if actual := resp.Header.Get("Content-Type"); actual != tt.thenContentType {
+				t.Errorf("encodingClient reply content type = '%s', want '%s'", actual, tt.thenContentType)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package cehandler
 import (
 	"context"
 
+	"github.com/alitari/ce-go-template/pkg/logging"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -20,14 +21,14 @@ type CeFilterHandler struct {
 	debug     bool
 }
 
-// NewCeFilterHandler start handling cloudEvents
-func NewCeFilterHandler(predicate CeFilter, ceClient cloudevents.Client, debug bool) (*CeFilterHandler, error) {
+// NewCeFilterHandler start handling cloudEvents until the context is done
+func NewCeFilterHandler(ctx context.Context, predicate CeFilter, ceClient cloudevents.Client, debug bool) (*CeFilterHandler, error) {
 	cph := new(CeFilterHandler)
 	cph.predicate = predicate
 	cph.ceClient = ceClient
 	cph.debug = debug
 
-	if err := cph.ceClient.StartReceiver(context.Background(), cph.HandleCe); err != nil {
+	if err := cph.ceClient.StartReceiver(ctx, cph.HandleCe); err != nil {
 		return nil, err
 	}
 	return cph, nil
@@ -37,7 +38,7 @@ func NewCeFilterHandler(predicate CeFilter, ceClient cloudevents.Client, debug b
 func (cph *CeFilterHandler) HandleCe(ctx context.Context, sourceEvent cloudevents.Event) (*cloudevents.Event, protocol.Result) {
 	reply, err := cph.predicate.PredicateEvent(&sourceEvent)
 	if err != nil {
-		return nil, http.NewResult(400, "got error %v while transforming event: %v", err, sourceEvent)
+		return nil, http.NewResult(400, "got error %v while transforming event %s", err, logging.EventRef(sourceEvent))
 	}
 	if reply {
 		
@@ -45,7 +45,7 @@ func TestCeFilterHandler_HandleCe(t *testing.T) {
 		{name: "Happy path filter blocked", givenIncomingEvent: incomingEvent, whenCeFilterPredicate: false,
 			thenWantResult: http.NewResult(204, "predicate is false"), thenWantOutgoingEvent: nil},
 		{name: "Filter error", givenIncomingEvent: incomingEvent, givenCeFilterError: errors.New("test"),
-			thenWantResult: http.NewResult(400, "got error %v while transforming event: %v", errors.New("test"), incomingEvent), thenWantOutgoingEvent: nil},
+			thenWantResult: http.NewResult(400, "got error %v while transforming event id='id' type='type' source='source'", errors.New("test")), thenWantOutgoingEvent: nil},
 		{name: "Client start error", givenCeClientStartError: errors.New("test"), thenWantFilterHandlerError: errors.New("test")},
 	}
 	for _, tt := range tests {
@@ -53,7 +53,7 @@ func TestCeFilterHandler_HandleCe(t *testing.T) {
 			ceFilter := &CeFilterMock{t: t, wantIncomingEvent: tt.givenIncomingEvent, wantPredicate: tt.whenCeFilterPredicate, shouldThrow: tt.givenCeFilterError}
 			ceClient := &cetransformer.CeClientMock{T: t, WantSend: false, ShouldThrowErrorOnStart: tt.givenCeClientStartError}
 
-			ceFilterHandler, err := NewCeFilterHandler(ceFilter, ceClient, true)
+			ceFilterHandler, err := NewCeFilterHandler(context.Background(), ceFilter, ceClient, true)
 			if !cetransformer.CompareErrors(t, "NewCeFilterHandler", err, tt.thenWantFilterHandlerError) {
 				return
 			}
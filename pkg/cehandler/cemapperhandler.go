@@ -2,8 +2,8 @@ package cehandler
 
 import (
 	"context"
-	"log"
 
+	"github.com/alitari/ce-go-template/pkg/logging"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -22,8 +22,8 @@ type CeMapperHandler struct {
 	debug       bool
 }
 
-// NewCeMapperHandler start handling cloudEvents
-func NewCeMapperHandler(ceMapper CeMapper, ceClient cloudevents.Client, sink string, debug bool) (*CeMapperHandler, error) {
+// NewCeMapperHandler start handling cloudEvents until the context is done
+func NewCeMapperHandler(ctx context.Context, ceMapper CeMapper, ceClient cloudevents.Client, sink string, debug bool) (*CeMapperHandler, error) {
 	ceh := new(CeMapperHandler)
 	ceh.transformer = ceMapper
 	ceh.ceClient = ceClient
@@ -35,7 +35,7 @@ func NewCeMapperHandler(ceMapper CeMapper, ceClient cloudevents.Client, sink str
 	} else {
 		receiver = ceh.ReceiveSendCe
 	}
-	if err := ceh.ceClient.StartReceiver(context.Background(), receiver); err != nil {
+	if err := ceh.ceClient.StartReceiver(ctx, receiver); err != nil {
 		return nil, err
 	}
 	return ceh, nil
@@ -45,10 +45,10 @@ func NewCeMapperHandler(ceMapper CeMapper, ceClient cloudevents.Client, sink str
 func (ceh *CeMapperHandler) ReceiveSendCe(ctx context.Context, sourceEvent cloudevents.Event) protocol.Result {
 	destEvent, err := ceh.transformer.TransformEvent(&sourceEvent)
 	if err != nil {
-		return http.NewResult(400, "got error %v while transforming event: %v", err, sourceEvent)
+		return http.NewResult(400, "got error %v while transforming event %s", err, logging.EventRef(sourceEvent))
 	}
 	if ceh.debug {
-		log.Printf("sending event: %v", destEvent)
+		logging.DebugEvent("sending event", *destEvent)
 	}
 	result := ceh.ceClient.Send(cloudevents.ContextWithTarget(ctx, ceh.sink), *destEvent)
 	return result
@@ -58,7 +58,7 @@ func (ceh *CeMapperHandler) ReceiveSendCe(ctx context.Context, sourceEvent cloud
 func (ceh *CeMapperHandler) ReceiveReplyCe(ctx context.Context, sourceEvent cloudevents.Event) (*cloudevents.Event, protocol.Result) {
 	destEvent, err := ceh.transformer.TransformEvent(&sourceEvent)
 	if err != nil {
-		return nil, http.NewResult(400, "got error %v while transforming event: %v", err, sourceEvent)
+		return nil, http.NewResult(400, "got error %v while transforming event %s", err, logging.EventRef(sourceEvent))
 	}
 	return destEvent, nil
 }
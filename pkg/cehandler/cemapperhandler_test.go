@@ -38,7 +38,7 @@ func TestCeMapperHandler_ReceiveSendCe(t *testing.T) {
 	}{
 		{name: "Happy path", givenCeMapperError: nil, givenCeClientStartError: nil, givenCeClientSendError: nil, thenWantMapperHandlerError: nil, thenWantResult: nil},
 		{name: "Mapper error", givenCeMapperError: errors.New("test"), givenCeClientStartError: nil, givenCeClientSendError: nil, thenWantMapperHandlerError: nil,
-			thenWantResult: http.NewResult(400, "got error %v while transforming event: %v", errors.New("test"), cetransformer.NewEventWithJSONStringData(`{"foo": "foo"}`))},
+			thenWantResult: http.NewResult(400, "got error %v while transforming event id='id' type='type' source='source'", errors.New("test"))},
 		{name: "Client start error", givenCeMapperError: nil, givenCeClientStartError: errors.New("test"), givenCeClientSendError: nil, thenWantMapperHandlerError: errors.New("test"), thenWantResult: nil},
 		{name: "Client send error", givenCeMapperError: nil, givenCeClientStartError: nil, givenCeClientSendError: errors.New("test"), thenWantMapperHandlerError: nil,
 			thenWantResult: errors.New("test")},
@@ -50,7 +50,7 @@ func TestCeMapperHandler_ReceiveSendCe(t *testing.T) {
 			ceMapper := &CeMapperMock{t: t, wantIncomingEvent: whenIncomingEvent, outgoingEvent: wantSendEvent, shouldThrow: tt.givenCeMapperError}
 			ceClient := &cetransformer.CeClientMock{T: t, WantSend: true, WantSendEvent: wantSendEvent, ShouldThrowErrorOnStart: tt.givenCeClientStartError, ShouldThrowErrorOnSend: tt.givenCeClientSendError}
 
-			ceMapperHandler, err := NewCeMapperHandler(ceMapper, ceClient, "sink", true)
+			ceMapperHandler, err := NewCeMapperHandler(context.Background(), ceMapper, ceClient, "sink", true)
 			if !cetransformer.CompareErrors(t, "NewCeMapperHandler", err, tt.thenWantMapperHandlerError) {
 				return
 			}
@@ -64,6 +64,42 @@ func TestCeMapperHandler_ReceiveSendCe(t *testing.T) {
 	}
 }
 
+// sentEventsClient keeps the sent events
+type sentEventsClient struct {
+	cloudevents.Client
+	sent []cloudevents.Event
+}
+
+func (c *sentEventsClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	c.sent = append(c.sent, event)
+	return nil
+}
+
+func TestCeMapperHandler_ReceiveSendCeExtensions(t *testing.T) {
+	ceMapper, err := cetransformer.NewCloudEventTransformerWithExtensions("{{ toJson .data }}", `{"tenant": {{ .data.tenant | quote }}, "priority": "high"}`, "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ceClient := &sentEventsClient{}
+	ceMapperHandler := &CeMapperHandler{transformer: ceMapper, ceClient: NewOverridesClient(ceClient, map[string]interface{}{"priority": "urgent"}), sink: "sink"}
+	whenIncomingEvent := cetransformer.NewEventWithJSONStringData(`{"tenant": "b"}`)
+	whenIncomingEvent.SetExtension("tenant", "a")
+	whenIncomingEvent.SetExtension("region", "eu")
+	whenIncomingEvent.SetExtension("priority", "low")
+
+	if result := ceMapperHandler.ReceiveSendCe(context.Background(), whenIncomingEvent); result != nil {
+		t.Fatalf("CeMapperHandler.ReceiveSendCe() result = %v", result)
+	}
+	if len(ceClient.sent) != 1 {
+		t.Fatalf("CeMapperHandler.ReceiveSendCe() sent %d events, want 1", len(ceClient.sent))
+	}
+	// incoming extensions are replaced by the template, the template by K_CE_OVERRIDES
+	thenExtensions := map[string]interface{}{"tenant": "b", "region": "eu", "priority": "urgent"}
+	if extensions := ceClient.sent[0].Extensions(); !cmp.Equal(extensions, thenExtensions) {
+		t.Errorf("CeMapperHandler.ReceiveSendCe() extensions of sent event = %v, want %v", extensions, thenExtensions)
+	}
+}
+
 func TestCeMapperHandler_ReceiveReplyCe(t *testing.T) {
 
 	tests := []struct {
@@ -75,7 +111,7 @@ func TestCeMapperHandler_ReceiveReplyCe(t *testing.T) {
 	}{
 		{name: "Happy path", givenCeMapperError: nil, givenCeClientStartError: nil, thenWantMapperHandlerError: nil, thenWantResult: nil},
 		{name: "Mapper error", givenCeMapperError: errors.New("test"), givenCeClientStartError: nil, thenWantMapperHandlerError: nil,
-			thenWantResult: http.NewResult(400, "got error %v while transforming event: %v", errors.New("test"), cetransformer.NewEventWithJSONStringData(`{"foo": "foo"}`))},
+			thenWantResult: http.NewResult(400, "got error %v while transforming event id='id' type='type' source='source'", errors.New("test"))},
 		{name: "Client start error", givenCeMapperError: nil, givenCeClientStartError: errors.New("test"), thenWantMapperHandlerError: errors.New("test"), thenWantResult: nil},
 	}
 	for _, tt := range tests {
@@ -85,7 +121,7 @@ func TestCeMapperHandler_ReceiveReplyCe(t *testing.T) {
 			ceMapper := &CeMapperMock{t: t, wantIncomingEvent: whenIncomingEvent, outgoingEvent: wantSendEvent, shouldThrow: tt.givenCeMapperError}
 			ceClient := &cetransformer.CeClientMock{T: t, WantSend: false, WantSendEvent: wantSendEvent, ShouldThrowErrorOnStart: tt.givenCeClientStartError}
 
-			ceMapperHandler, err := NewCeMapperHandler(ceMapper, ceClient, "sink", true)
+			ceMapperHandler, err := NewCeMapperHandler(context.Background(), ceMapper, ceClient, "sink", true)
 			if !cetransformer.CompareErrors(t, "NewCeMapperHandler", err, tt.thenWantMapperHandlerError) {
 				return
 			}
@@ -0,0 +1,63 @@
+package cehandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// ceOverrides the json of the K_CE_OVERRIDES env variable injected by knative sources and sink bindings
+type ceOverrides struct {
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// ParseOverrides the extensions of K_CE_OVERRIDES, e.g. '{"extensions":{"tenant":"a"}}'. An empty value has no extensions
+func ParseOverrides(overridesJSON string) (map[string]interface{}, error) {
+	if overridesJSON == "" {
+		return nil, nil
+	}
+	overrides := ceOverrides{}
+	if err := json.Unmarshal([]byte(overridesJSON), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid ce overrides: %v", err)
+	}
+	event := cloudevents.NewEvent()
+	for name, value := range overrides.Extensions {
+		if err := event.Context.SetExtension(name, value); err != nil {
+			return nil, fmt.Errorf("invalid ce overrides extension '%s': %v", name, err)
+		}
+	}
+	return overrides.Extensions, nil
+}
+
+// overridesClient sets the extensions on every sent event
+type overridesClient struct {
+	cloudevents.Client
+	extensions map[string]interface{}
+}
+
+// NewOverridesClient the extensions replace extensions with the same name of a sent event. Replies are not changed
+func NewOverridesClient(client cloudevents.Client, extensions map[string]interface{}) cloudevents.Client {
+	if len(extensions) == 0 {
+		return client
+	}
+	return &overridesClient{Client: client, extensions: extensions}
+}
+
+func (c *overridesClient) override(event cloudevents.Event) cloudevents.Event {
+	event = event.Clone()
+	for name, value := range c.extensions {
+		event.SetExtension(name, value)
+	}
+	return event
+}
+
+func (c *overridesClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	return c.Client.Send(ctx, c.override(event))
+}
+
+func (c *overridesClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	return c.Client.Request(ctx, c.override(event))
+}
@@ -0,0 +1,54 @@
+package cehandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestParseOverrides(t *testing.T) {
+	tests := []struct {
+		name           string
+		whenOverrides  string
+		thenExtensions map[string]interface{}
+		thenError      bool
+	}{
+		{name: "empty"},
+		{name: "extensions", whenOverrides: `{"extensions": {"tenant": "a", "cluster": "b"}}`, thenExtensions: map[string]interface{}{"tenant": "a", "cluster": "b"}},
+		{name: "no extensions", whenOverrides: `{}`},
+		{name: "invalid json", whenOverrides: `{"extensions": `, thenError: true},
+		{name: "invalid name", whenOverrides: `{"extensions": {"ten-ant": "a"}}`, thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extensions, err := ParseOverrides(tt.whenOverrides)
+			if (err != nil) != tt.thenError {
+				t.Fatalf("ParseOverrides() error = %v, want error %v", err, tt.thenError)
+			}
+			if len(extensions) != 0 || len(tt.thenExtensions) != 0 {
+				if !reflect.DeepEqual(extensions, tt.thenExtensions) {
+					t.Errorf("ParseOverrides() = %v, want %v", extensions, tt.thenExtensions)
+				}
+			}
+		})
+	}
+}
+
+func TestOverridesClient_Send(t *testing.T) {
+	event := cetransformer.NewEventWithJSONStringData(`{"foo": "foo"}`)
+	event.SetExtension("tenant", "event")
+	event.SetExtension("region", "eu")
+	wantEvent := event.Clone()
+	wantEvent.SetExtension("tenant", "override")
+	wantEvent.SetExtension("cluster", "c1")
+	client := NewOverridesClient(&cetransformer.CeClientMock{T: t, WantSend: true, WantSendEvent: wantEvent}, map[string]interface{}{"tenant": "override", "cluster": "c1"})
+	if result := client.Send(context.Background(), event); !cloudevents.IsACK(result) {
+		t.Errorf("overridesClient.Send() result = %v", result)
+	}
+	if event.Extensions()["tenant"] != "event" {
+		t.Errorf("overridesClient.Send() changed the extension of the sent event to '%v'", event.Extensions()["tenant"])
+	}
+}
@@ -3,10 +3,11 @@ package cehandler
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
+	"github.com/alitari/ce-go-template/pkg/logging"
+	"github.com/alitari/ce-go-template/pkg/outbox"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
 )
@@ -23,6 +24,7 @@ type CeProducerHandler struct {
 	sink     string
 	debug    bool
 	timeout  time.Duration
+	outbox   *outbox.Outbox
 }
 
 // NewProducerHandler create new instance
@@ -31,30 +33,48 @@ func NewProducerHandler(producer CeProducer, ceClient cloudevents.Client, sink s
 	return &cph
 }
 
-// SendCe producer and send cloudEvent
+// WithOutbox events are written to the outbox and delivered by its worker with Deliver
+func (cph *CeProducerHandler) WithOutbox(eventOutbox *outbox.Outbox) *CeProducerHandler {
+	cph.outbox = eventOutbox
+	return cph
+}
+
+// SendCe producer and send cloudEvent, with an outbox the event is only written to it
 func (cph *CeProducerHandler) SendCe(input interface{}) error {
 	destEvent, err := cph.producer.CreateEvent(input)
 	if err != nil {
-		return http.NewResult(400, "got error %v while producing event from input : %v", err, input)
+		return http.NewResult(400, "got error %v while producing event from input of type %T", err, input)
+	}
+	if cph.outbox != nil {
+		return cph.outbox.Add(*destEvent)
 	}
+	return cph.Deliver(*destEvent)
+}
+
+// Deliver sends the event to the sink. Status 4xx except 429 is a permanent error for the outbox
+func (cph *CeProducerHandler) Deliver(destEvent cloudevents.Event) error {
 	if cph.debug {
-		log.Printf("sending event: %v", destEvent)
+		logging.DebugEvent("sending event", destEvent)
 	}
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), cph.timeout)
 	defer cancel()
 	sendContext := cloudevents.ContextWithTarget(timeoutCtx, cph.sink)
-	result := cph.ceClient.Send(sendContext, *destEvent)
+	result := cph.ceClient.Send(sendContext, destEvent)
 	if result != nil {
 		if !strings.HasPrefix(result.Error(), "20") {
-			return fmt.Errorf("Failed to send event! error: %v", result.Error())
+			err := fmt.Errorf("Failed to send event! error: %v", result.Error())
+			var httpResult *http.Result
+			if cloudevents.ResultAs(result, &httpResult) && httpResult.StatusCode >= 400 && httpResult.StatusCode < 500 && httpResult.StatusCode != 429 {
+				return outbox.Permanent(err)
+			}
+			return err
 		}
 		if cloudevents.IsUndelivered(result) {
 			return fmt.Errorf("Event was not delivered: %v", result)
 		}
 		if cph.debug {
-			log.Printf("Event successfully delivered: %s", result.Error())
+			logging.ForEvent(destEvent).Debugf("event delivered: %s", result.Error())
 		}
 	}
 	return nil
-	
-}
\ No newline at end of file
+}
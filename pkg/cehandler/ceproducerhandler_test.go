@@ -2,11 +2,14 @@ package cehandler
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/outbox"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/cloudevents/sdk-go/v2/protocol/http"
@@ -42,7 +45,7 @@ func TestCeProducerHandler_SendCe(t *testing.T) {
 		{name: "Happy path ", whenProducerEvent: &outgoingEvent, thenWantOutgoingEvent: &outgoingEvent},
 		{name: "Client send error ", givenCeClientSendError: errors.New("test"), whenProducerEvent: &outgoingEvent, thenWantResult: errors.New("Failed to send event! error: test")},
 		{name: "Producer error ", givenProducerError: errors.New("test"), whenProducerEvent: &outgoingEvent,
-			thenWantResult: http.NewResult(400, "got error %v while producing event from input : %v", errors.New("test"), input)},
+			thenWantResult: http.NewResult(400, "got error %v while producing event from input of type %T", errors.New("test"), input)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -56,3 +59,45 @@ func TestCeProducerHandler_SendCe(t *testing.T) {
 		})
 	}
 }
+
+func TestCeProducerHandler_outbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	eventOutbox, err := outbox.Open(dir, time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ceProducer := &CeProducerMock{t: t, wantInput: input, outgoingEvent: outgoingEvent}
+	ceClient := &cetransformer.CeClientMock{T: t, WantSend: false}
+	ceProducerHandler := NewProducerHandler(ceProducer, ceClient, "sink", 3*time.Second, true).WithOutbox(eventOutbox)
+	if err := ceProducerHandler.SendCe(input); err != nil {
+		t.Errorf("CeProducerHandler.SendCe() with outbox error = %v", err)
+	}
+	if eventOutbox.Depth() != 1 {
+		t.Errorf("CeProducerHandler.SendCe() outbox depth = %d, want 1", eventOutbox.Depth())
+	}
+}
+
+func TestCeProducerHandler_Deliver(t *testing.T) {
+	tests := []struct {
+		name                   string
+		givenCeClientSendError error
+		thenPermanent          bool
+	}{
+		{name: "unavailable", givenCeClientSendError: http.NewResult(503, "unavailable")},
+		{name: "too many requests", givenCeClientSendError: http.NewResult(429, "too many requests")},
+		{name: "bad request", givenCeClientSendError: http.NewResult(400, "bad request"), thenPermanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ceClient := &cetransformer.CeClientMock{T: t, WantSend: true, WantSendEvent: outgoingEvent, ShouldThrowErrorOnSend: tt.givenCeClientSendError}
+			err := NewProducerHandler(nil, ceClient, "sink", 3*time.Second, false).Deliver(outgoingEvent)
+			if err == nil || outbox.IsPermanent(err) != tt.thenPermanent {
+				t.Errorf("CeProducerHandler.Deliver() error = %v, want permanent %v", err, tt.thenPermanent)
+			}
+		})
+	}
+}
@@ -2,12 +2,13 @@ package cehttpclienttransformer
 
 import (
 	"bytes"
-	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
 
 	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/limiter"
 	"github.com/alitari/ce-go-template/pkg/transformer"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 )
@@ -17,41 +18,73 @@ type HTTPSender interface {
 	Send() (*http.Response, error)
 }
 
+// requestSender HTTPSender which knows the request it sends
+type requestSender interface {
+	HTTPSender
+	Request() *http.Request
+}
+
 // Config bla
 type Config struct {
-	SenderCreator func(string, time.Duration, bool) (HTTPSender, error)
-	RequestTemplate  string
-	ResponseTemplate    string
-	Timeout       time.Duration
-	JSONBody      bool
-	OnlyPayload   bool
-	Debug         bool
+	SenderCreator    func(string, time.Duration, bool) (HTTPSender, error)
+	Steps            []Step
+	ResponseTemplate string
+	Timeout          time.Duration
+	JSONBody         bool
+	OnlyPayload      bool
+	Debug            bool
+	GraphQL          *GraphQL
 }
 
 // CeHTTPClientTransformer bla
 type CeHTTPClientTransformer struct {
-	config          Config
-	request         *http.Request
-	client          *http.Client
-	httpTransformer *transformer.Transformer
-	ceTransformer   *transformer.Transformer
+	config        Config
+	steps         []*step
+	ceTransformer *transformer.Transformer
+	cache         *ResponseCache
+	rateLimiter   *limiter.RateLimiter
 }
 
 // NewCeHTTPClientTransformer bla
 func NewCeHTTPClientTransformer(requestTemplate string, responseTemplate string, timeout time.Duration, jsonBody bool, debug bool) (*CeHTTPClientTransformer, error) {
-	return ceHTTPClientTransformer(func(protocol string, timeOut time.Duration, debug bool) (HTTPSender, error) {
-		return NewHTTPProtocolSender(protocol, timeOut, debug)
-	}, requestTemplate, responseTemplate, timeout, jsonBody, debug)
+	return ceHTTPClientTransformer(newHTTPProtocolSender, requestTemplate, responseTemplate, timeout, jsonBody, debug)
+}
+
+// NewCeHTTPClientChainTransformer executes a chain of HTTP requests for every event
+func NewCeHTTPClientChainTransformer(steps []Step, responseTemplate string, timeout time.Duration, jsonBody bool, debug bool) (*CeHTTPClientTransformer, error) {
+	return newCeHTTPClientTransformer(Config{SenderCreator: newHTTPProtocolSender, Steps: steps, ResponseTemplate: responseTemplate, Timeout: timeout, JSONBody: jsonBody, Debug: debug})
+}
+
+// NewCeHTTPClientTransformerWithConfig the requests are sent by the HTTPSender of the config, if it is nil as http requests. With GraphQL the query is the only request
+func NewCeHTTPClientTransformerWithConfig(config Config) (*CeHTTPClientTransformer, error) {
+	if config.SenderCreator == nil {
+		config.SenderCreator = newHTTPProtocolSender
+	}
+	if config.GraphQL != nil {
+		var err error
+		if config, err = graphQLConfig(config); err != nil {
+			return nil, err
+		}
+	}
+	return newCeHTTPClientTransformer(config)
+}
+
+func newHTTPProtocolSender(protocol string, timeOut time.Duration, debug bool) (HTTPSender, error) {
+	return NewHTTPProtocolSender(protocol, timeOut, debug)
 }
 
 func ceHTTPClientTransformer(senderCreator func(string, time.Duration, bool) (HTTPSender, error), requestTemplate string, responseTemplate string, timeout time.Duration, jsonBody bool, debug bool) (*CeHTTPClientTransformer, error) {
+	return newCeHTTPClientTransformer(Config{SenderCreator: senderCreator, Steps: []Step{{Name: "request", RequestTemplate: requestTemplate}}, ResponseTemplate: responseTemplate, Timeout: timeout, JSONBody: jsonBody, Debug: debug})
+}
+
+func newCeHTTPClientTransformer(config Config) (*CeHTTPClientTransformer, error) {
 	cht := new(CeHTTPClientTransformer)
-	cht.config = Config{SenderCreator: senderCreator, RequestTemplate: requestTemplate, ResponseTemplate: responseTemplate, Timeout: timeout, JSONBody: jsonBody, Debug: debug}
-	httpTransformer, err := transformer.NewTransformer(cht.config.RequestTemplate, nil, cht.config.Debug)
+	cht.config = config
+	steps, err := newSteps(cht.config.Steps, cht.config.Debug)
 	if err != nil {
 		return nil, err
 	}
-	cht.httpTransformer = httpTransformer
+	cht.steps = steps
 	ceTransformer, err := transformer.NewTransformer(cht.config.ResponseTemplate, nil, cht.config.Debug)
 	if err != nil {
 		return nil, err
@@ -61,27 +94,56 @@ func ceHTTPClientTransformer(senderCreator func(string, time.Duration, bool) (HT
 	return cht, nil
 }
 
-func (ct *CeHTTPClientTransformer) transformEventToBytes(sourceEvent *cloudevents.Event) ([]byte, error) {
-	inputEventData := cetransformer.EventToMap(sourceEvent)
-	httpBytes, err := ct.httpTransformer.TransformInputToBytes(inputEventData)
-	if err != nil {
-		return nil, err
-	}
-	sender, err := ct.config.SenderCreator(string(httpBytes), ct.config.Timeout, ct.config.Debug)
-	if err != nil {
-		return nil, err
+// WithResponseCache answers requests from cache if possible
+func (ct *CeHTTPClientTransformer) WithResponseCache(cache *ResponseCache) *CeHTTPClientTransformer {
+	senderCreator := ct.config.SenderCreator
+	ct.cache = cache
+	ct.config.SenderCreator = func(protocol string, timeout time.Duration, debug bool) (HTTPSender, error) {
+		sender, err := senderCreator(protocol, timeout, debug)
+		if err != nil {
+			return nil, err
+		}
+		rs, ok := sender.(requestSender)
+		if !ok {
+			return nil, fmt.Errorf("response cache needs the request of the sender, %T does not provide it", sender)
+		}
+		return NewCachingSender(cache, rs.Request(), sender, debug), nil
 	}
-	resp, err := sender.Send()
-	if err != nil {
-		return nil, err
+	return ct
+}
+
+// WithRateLimiter every request waits for a token of the rate limiter, the key template gets the input of the request template
+func (ct *CeHTTPClientTransformer) WithRateLimiter(rateLimiter *limiter.RateLimiter) *CeHTTPClientTransformer {
+	ct.rateLimiter = rateLimiter
+	return ct
+}
+
+// CacheStats statistics of the response cache, zero if caching is disabled
+func (ct *CeHTTPClientTransformer) CacheStats() CacheStats {
+	if ct.cache == nil {
+		return CacheStats{}
 	}
-	respData, err := ResponseToMap(resp, ct.config.JSONBody)
+	return ct.cache.Stats()
+}
+
+func (ct *CeHTTPClientTransformer) transformEventToBytes(sourceEvent *cloudevents.Event) ([]byte, error) {
+	inputEventData := cetransformer.EventToMap(sourceEvent)
+	stepResponses, respData, err := ct.runSteps(inputEventData)
 	if err != nil {
 		return nil, err
 	}
 	input := map[string]interface{}{}
 	input["inputce"] = inputEventData
 	input["httpresponse"] = respData
+	input["steps"] = stepResponses
+	if ct.config.GraphQL != nil {
+		data, errors, err := ct.config.GraphQL.graphQLResult(respData)
+		if err != nil {
+			return nil, err
+		}
+		input["data"] = data
+		input["errors"] = errors
+	}
 	eventBytes, err := ct.ceTransformer.TransformInputToBytes(input)
 	if err != nil {
 		return nil, err
@@ -103,6 +165,9 @@ func (ct *CeHTTPClientTransformer) TransformEvent(sourceEvent *cloudevents.Event
 	if ct.config.OnlyPayload {
 		result.Context = sourceEvent.Context.Clone()
 	}
+	if sourceEvent.ID() != "" {
+		result.SetID(cetransformer.DeriveID(sourceEvent, result.Type()))
+	}
 	return &result, nil
 }
 
@@ -123,16 +188,21 @@ func ResponseToMap(response *http.Response, jsonBody bool) (map[string]interface
 		responseMap["header"] = response.Header
 		responseMap["status"] = response.Status
 		responseMap["statusCode"] = response.StatusCode
+		if response.Request != nil && response.Request.URL != nil {
+			responseMap["url"] = response.Request.URL.String()
+		}
 		if response.Body != nil {
 			b := new(bytes.Buffer)
 			io.Copy(b, response.Body)
 			response.Body.Close()
+			responseMap["rawBody"] = b.String()
+			responseMap["contentLength"] = b.Len()
 			if jsonBody {
-				bodyData := map[string]interface{}{}
-				if err := json.Unmarshal(b.Bytes(), &bodyData); err != nil {
-					return nil, err
+				body, err := DecodeBody(response.Header.Get("Content-Type"), b.Bytes())
+				if err != nil {
+					return nil, fmt.Errorf("can't decode response body with content type '%s': %v", response.Header.Get("Content-Type"), err)
 				}
-				responseMap["body"] = bodyData
+				responseMap["body"] = body
 			} else {
 				responseMap["body"] = b.String()
 			}
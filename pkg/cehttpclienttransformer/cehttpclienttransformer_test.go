@@ -103,6 +103,9 @@ content-type: application/json
 				return
 			}
 			cetransformer.CompareEvents(t, "cehttpclienttransformer.TransformEvent", *outgoingCe, tt.thenWantOutgoingEvent)
+			if wantID := cetransformer.DeriveID(&tt.whenIncomingEvent, outgoingCe.Type()); outgoingCe.ID() != wantID {
+				t.Errorf("cehttpclienttransformer.TransformEvent id = %s, want derived id %s", outgoingCe.ID(), wantID)
+			}
 		})
 	}
 }
@@ -181,7 +184,23 @@ func TestResponseToMap(t *testing.T) {
 		{name: "Simple",
 			givenJSONBody: true,
 			whenResponse:  &http.Response{Header: http.Header{"Content-Type": {"application/json"}}, Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{ "name": "Alex", "gender": "male" }`))},
-			thenWant:      map[string]interface{}{"header": http.Header{"Content-Type": {"application/json"}}, "body": map[string]interface{}{"name": "Alex", "gender": "male"}, "status": "200 OK", "statusCode": 200}, thenWantErr: false},
+			thenWant:      map[string]interface{}{"header": http.Header{"Content-Type": {"application/json"}}, "body": map[string]interface{}{"name": "Alex", "gender": "male"}, "rawBody": `{ "name": "Alex", "gender": "male" }`, "contentLength": 36, "status": "200 OK", "statusCode": 200}, thenWantErr: false},
+		{name: "JSON array",
+			givenJSONBody: true,
+			whenResponse:  &http.Response{Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}}, Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`[1,2]`))},
+			thenWant:      map[string]interface{}{"header": http.Header{"Content-Type": {"application/json; charset=utf-8"}}, "body": []interface{}{1.0, 2.0}, "rawBody": `[1,2]`, "contentLength": 5, "status": "200 OK", "statusCode": 200}, thenWantErr: false},
+		{name: "text",
+			givenJSONBody: true,
+			whenResponse:  &http.Response{Header: http.Header{"Content-Type": {"text/plain"}}, Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`hello`)), Request: cetransformer.NewGETRequest("http://localhost:8080/final")},
+			thenWant:      map[string]interface{}{"header": http.Header{"Content-Type": {"text/plain"}}, "body": "hello", "rawBody": `hello`, "contentLength": 5, "status": "200 OK", "statusCode": 200, "url": "http://localhost:8080/final"}, thenWantErr: false},
+		{name: "no JSON body",
+			givenJSONBody: false,
+			whenResponse:  &http.Response{Header: http.Header{"Content-Type": {"application/json"}}, Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{}`))},
+			thenWant:      map[string]interface{}{"header": http.Header{"Content-Type": {"application/json"}}, "body": "{}", "rawBody": `{}`, "contentLength": 2, "status": "200 OK", "statusCode": 200}, thenWantErr: false},
+		{name: "invalid JSON",
+			givenJSONBody: true,
+			whenResponse:  &http.Response{Header: http.Header{"Content-Type": {"application/json"}}, Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{`))},
+			thenWantErr:   true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,136 @@
+package cehttpclienttransformer
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+)
+
+// CircuitBreaker rejects requests to a target (host:port) after consecutive failures until the cooldown is over.
+// After the cooldown one request is let through, its result closes or opens the circuit again
+type CircuitBreaker struct {
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	targets  map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker opens the circuit of a target after failures consecutive failures for the cooldown
+func NewCircuitBreaker(failures int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failures: failures, cooldown: cooldown, now: time.Now, targets: map[string]*circuit{}}
+}
+
+// Allow returns an error if the circuit of the target is open
+func (cb *CircuitBreaker) Allow(target string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.targets[target]
+	if c == nil || !c.open {
+		return nil
+	}
+	if c.probing || cb.now().Sub(c.openedAt) < cb.cooldown {
+		return fmt.Errorf("circuit breaker is open for '%s'", target)
+	}
+	c.probing = true
+	return nil
+}
+
+// Success closes the circuit of the target
+func (cb *CircuitBreaker) Success(target string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if c := cb.targets[target]; c != nil {
+		if c.open {
+			logging.Infof("circuit breaker closed for '%s'", target)
+		}
+		delete(cb.targets, target)
+	}
+}
+
+// Failure counts a failure of the target and opens its circuit if the limit is reached
+func (cb *CircuitBreaker) Failure(target string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	c := cb.targets[target]
+	if c == nil {
+		c = &circuit{}
+		cb.targets[target] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.open || c.failures >= cb.failures {
+		if !c.open {
+			logging.Warnf("circuit breaker opened for '%s' after %d failures", target, c.failures)
+		}
+		c.open = true
+		c.openedAt = cb.now()
+	}
+}
+
+// OpenTargets the targets whose circuit is open and whose cooldown is not over
+func (cb *CircuitBreaker) OpenTargets() []string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	targets := []string{}
+	for target, c := range cb.targets {
+		if c.open && cb.now().Sub(c.openedAt) < cb.cooldown {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// breakerSender sends the request only if the circuit of its host is closed. Errors and status 5xx are failures
+type breakerSender struct {
+	breaker *CircuitBreaker
+	request *http.Request
+	sender  HTTPSender
+}
+
+func (bs *breakerSender) Request() *http.Request {
+	return bs.request
+}
+
+func (bs *breakerSender) Send() (*http.Response, error) {
+	target := bs.request.URL.Host
+	if err := bs.breaker.Allow(target); err != nil {
+		return nil, err
+	}
+	response, err := bs.sender.Send()
+	if err != nil || response.StatusCode >= 500 {
+		bs.breaker.Failure(target)
+	} else {
+		bs.breaker.Success(target)
+	}
+	return response, err
+}
+
+// WithCircuitBreaker rejects requests to targets with open circuit
+func (ct *CeHTTPClientTransformer) WithCircuitBreaker(breaker *CircuitBreaker) *CeHTTPClientTransformer {
+	senderCreator := ct.config.SenderCreator
+	ct.config.SenderCreator = func(protocol string, timeout time.Duration, debug bool) (HTTPSender, error) {
+		sender, err := senderCreator(protocol, timeout, debug)
+		if err != nil {
+			return nil, err
+		}
+		rs, ok := sender.(requestSender)
+		if !ok {
+			return nil, fmt.Errorf("circuit breaker needs the request of the sender, %T does not provide it", sender)
+		}
+		return &breakerSender{breaker: breaker, request: rs.Request(), sender: sender}, nil
+	}
+	return ct
+}
@@ -0,0 +1,137 @@
+package cehttpclienttransformer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name            string
+		givenStatus     []int
+		givenAdvance    time.Duration
+		whenSends       int
+		thenWantCalls   int
+		thenWantOpen    []string
+		thenWantAllowed bool
+	}{
+		{name: "closed", givenStatus: []int{200, 200, 200}, whenSends: 3, thenWantCalls: 3, thenWantOpen: []string{}, thenWantAllowed: true},
+		{name: "success resets failures", givenStatus: []int{500, 200, 500, 200}, whenSends: 4, thenWantCalls: 4, thenWantOpen: []string{}, thenWantAllowed: true},
+		{name: "opens after failures", givenStatus: []int{500, 503}, whenSends: 4, thenWantCalls: 2, thenWantOpen: []string{"localhost:8080"}, thenWantAllowed: false},
+		{name: "half open after cooldown", givenStatus: []int{500, 500}, givenAdvance: time.Minute, whenSends: 2, thenWantCalls: 2, thenWantOpen: []string{}, thenWantAllowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			breaker := NewCircuitBreaker(2, 30*time.Second)
+			breaker.now = func() time.Time { return now }
+			responses := []http.Response{}
+			for _, status := range tt.givenStatus {
+				responses = append(responses, http.Response{StatusCode: status, Header: http.Header{}})
+			}
+			request, _ := http.NewRequest("GET", "http://localhost:8080/customer", nil)
+			counting := &CountingHTTPSender{request: request, responses: responses}
+			sender := &breakerSender{breaker: breaker, request: request, sender: counting}
+			for i := 0; i < tt.whenSends; i++ {
+				sender.Send()
+			}
+			if counting.calls != tt.thenWantCalls {
+				t.Errorf("breakerSender.Send() calls = %d, want %d", counting.calls, tt.thenWantCalls)
+			}
+			now = now.Add(tt.givenAdvance)
+			if open := breaker.OpenTargets(); !reflect.DeepEqual(open, tt.thenWantOpen) {
+				t.Errorf("CircuitBreaker.OpenTargets() = %v, want %v", open, tt.thenWantOpen)
+			}
+			if allowed := breaker.Allow("localhost:8080") == nil; allowed != tt.thenWantAllowed {
+				t.Errorf("CircuitBreaker.Allow() = %v, want %v", allowed, tt.thenWantAllowed)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_probe(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Second)
+	breaker.now = func() time.Time { return now }
+	breaker.Failure("a")
+	now = now.Add(2 * time.Second)
+	if err := breaker.Allow("a"); err != nil {
+		t.Fatalf("CircuitBreaker.Allow() probe error = %v", err)
+	}
+	if err := breaker.Allow("a"); err == nil {
+		t.Error("CircuitBreaker.Allow() second request during probe, want error")
+	}
+	breaker.Failure("a")
+	if err := breaker.Allow("a"); err == nil {
+		t.Error("CircuitBreaker.Allow() after failed probe, want error")
+	}
+	now = now.Add(2 * time.Second)
+	breaker.Allow("a")
+	breaker.Success("a")
+	if err := breaker.Allow("a"); err != nil {
+		t.Errorf("CircuitBreaker.Allow() after successful probe error = %v", err)
+	}
+}
+
+func TestWithCircuitBreaker_responseCache(t *testing.T) {
+	tests := []struct {
+		name              string
+		givenCacheOutside bool
+	}{
+		{name: "cache inside breaker"},
+		{name: "cache outside breaker", givenCacheOutside: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+			ct, err := NewCeHTTPClientTransformer("GET "+server.URL+" HTTP/1.1\n\n", `{}`, time.Second, true, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			breaker := NewCircuitBreaker(2, time.Minute)
+			cache := NewResponseCache(10, time.Minute, nil)
+			if tt.givenCacheOutside {
+				ct.WithCircuitBreaker(breaker).WithResponseCache(cache)
+			} else {
+				ct.WithResponseCache(cache).WithCircuitBreaker(breaker)
+			}
+			for i := 0; i < 4; i++ {
+				incomingEvent := cetransformer.NewEventWithJSONStringData(`{}`)
+				ct.TransformEvent(&incomingEvent)
+			}
+			if calls := atomic.LoadInt32(&calls); calls != 2 {
+				t.Errorf("server calls = %d, want 2", calls)
+			}
+			serverURL, _ := url.Parse(server.URL)
+			if open := breaker.OpenTargets(); !reflect.DeepEqual(open, []string{serverURL.Host}) {
+				t.Errorf("CircuitBreaker.OpenTargets() = %v, want %v", open, []string{serverURL.Host})
+			}
+		})
+	}
+}
+
+func TestWithCircuitBreaker_senderWithoutRequest(t *testing.T) {
+	ct, err := newCeHTTPClientTransformer(Config{SenderCreator: func(protocol string, timeout time.Duration, debug bool) (HTTPSender, error) {
+		return &MockHTTPSender{}, nil
+	}, Steps: []Step{{Name: "get", RequestTemplate: "GET http://localhost:8080 HTTP/1.1\n\n"}}, ResponseTemplate: `{}`, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct.WithCircuitBreaker(NewCircuitBreaker(2, time.Minute))
+	incomingEvent := cetransformer.NewEventWithJSONStringData(`{}`)
+	if _, err := ct.TransformEvent(&incomingEvent); err == nil {
+		t.Error("TransformEvent() with a sender without request, want error")
+	}
+}
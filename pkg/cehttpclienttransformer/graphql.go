@@ -0,0 +1,96 @@
+package cehttpclienttransformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GraphQL configuration of a GraphQL request
+type GraphQL struct {
+	URL   string
+	Query string
+	// VariablesTemplate template rendering the json object of the query variables
+	VariablesTemplate string
+	Headers           map[string]string
+	// FailOnErrors treat a response with a non empty `errors` array as failure
+	FailOnErrors bool
+}
+
+// NewCeHTTPClientGraphQLTransformer sends a GraphQL query for every event. The response template sees the GraphQL `data` and `errors` directly
+func NewCeHTTPClientGraphQLTransformer(graphQL GraphQL, responseTemplate string, timeout time.Duration, debug bool) (*CeHTTPClientTransformer, error) {
+	return NewCeHTTPClientTransformerWithConfig(Config{ResponseTemplate: responseTemplate, Timeout: timeout, Debug: debug, GraphQL: &graphQL})
+}
+
+// graphQLConfig the config sends the GraphQL query as its only step
+func graphQLConfig(config Config) (Config, error) {
+	if len(config.Steps) > 0 {
+		return config, fmt.Errorf("GraphQL mode can't have request steps")
+	}
+	requestTemplate, err := config.GraphQL.requestTemplate()
+	if err != nil {
+		return config, err
+	}
+	config.Steps = []Step{{Name: "graphql", RequestTemplate: requestTemplate}}
+	config.JSONBody = true
+	return config, nil
+}
+
+// requestTemplate creates a template of a structured json request with the query as constant and the output of the variables template as variables
+func (gq GraphQL) requestTemplate() (string, error) {
+	if gq.URL == "" {
+		return "", fmt.Errorf("GraphQL url is missing")
+	}
+	if strings.TrimSpace(gq.Query) == "" {
+		return "", fmt.Errorf("GraphQL query is missing")
+	}
+	variablesTemplate := gq.VariablesTemplate
+	if strings.TrimSpace(variablesTemplate) == "" {
+		variablesTemplate = "{}"
+	}
+	headers := map[string]string{"Content-Type": "application/json", "Accept": "application/json"}
+	for k, v := range gq.Headers {
+		headers[k] = v
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+	urlJSON, err := json.Marshal(gq.URL)
+	if err != nil {
+		return "", err
+	}
+	queryJSON, err := json.Marshal(gq.Query)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"method":"POST","url":%s,"headers":%s,"json":{"query":%s,"variables":%s}}`,
+		escapeTemplateText(urlJSON), escapeTemplateText(headersJSON), escapeTemplateText(queryJSON), variablesTemplate), nil
+}
+
+// escapeTemplateText quotes action delimiters of constant text which becomes part of a template
+func escapeTemplateText(text []byte) string {
+	return strings.ReplaceAll(string(text), "{{", `{{"{{"}}`)
+}
+
+// graphQLResult extracts `data` and `errors` from a GraphQL response
+func (gq GraphQL) graphQLResult(response map[string]interface{}) (interface{}, interface{}, error) {
+	body, ok := response["body"].(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("GraphQL response has status '%v' and no json object body: '%v'", response["status"], response["rawBody"])
+	}
+	errors, _ := body["errors"].([]interface{})
+	if gq.FailOnErrors && len(errors) > 0 {
+		messages := []string{}
+		for _, e := range errors {
+			if m, ok := e.(map[string]interface{}); ok {
+				messages = append(messages, fmt.Sprint(m["message"]))
+			} else {
+				messages = append(messages, fmt.Sprint(e))
+			}
+		}
+		return nil, nil, fmt.Errorf("GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+	return body["data"], body["errors"], nil
+}
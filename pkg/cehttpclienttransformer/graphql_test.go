@@ -0,0 +1,90 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestTransformEventGraphQL(t *testing.T) {
+	tests := []struct {
+		name                  string
+		givenGraphQL          GraphQL
+		givenCeTemplate       string
+		givenHTTPResponse     string
+		whenIncomingEvent     cloudevents.Event
+		thenWantRequestBody   map[string]interface{}
+		thenWantOutgoingEvent cloudevents.Event
+		thenWantErr           bool
+	}{
+		{name: "query with variables",
+			givenGraphQL: GraphQL{URL: "http://localhost:8080/graphql", Query: "query($id: ID!) {\n  customer(id: $id) { name \"{{\" }\n}",
+				VariablesTemplate: `{ "id": {{ .data.id | quote }} }`, Headers: map[string]string{"Authorization": "Bearer x"}, FailOnErrors: true},
+			givenCeTemplate:       `{{ .data.customer | toJson }}`,
+			givenHTTPResponse:     `{ "data": { "customer": { "name": "Alex" } } }`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "id": "4711" }`),
+			thenWantRequestBody:   map[string]interface{}{"query": "query($id: ID!) {\n  customer(id: $id) { name \"{{\" }\n}", "variables": map[string]interface{}{"id": "4711"}},
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "name": "Alex" }`, "", ""),
+		},
+		{name: "errors fail",
+			givenGraphQL:        GraphQL{URL: "http://localhost:8080/graphql", Query: "{ customers { name } }", FailOnErrors: true},
+			givenCeTemplate:     `{}`,
+			givenHTTPResponse:   `{ "data": null, "errors": [ { "message": "not allowed" } ] }`,
+			whenIncomingEvent:   cetransformer.NewEventWithJSONStringData(`{}`),
+			thenWantRequestBody: map[string]interface{}{"query": "{ customers { name } }", "variables": map[string]interface{}{}},
+			thenWantErr:         true,
+		},
+		{name: "errors exposed",
+			givenGraphQL:          GraphQL{URL: "http://localhost:8080/graphql", Query: "{ customers { name } }"},
+			givenCeTemplate:       `{ "error": {{ (first .errors).message | quote }} }`,
+			givenHTTPResponse:     `{ "data": null, "errors": [ { "message": "not allowed" } ] }`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{}`),
+			thenWantRequestBody:   map[string]interface{}{"query": "{ customers { name } }", "variables": map[string]interface{}{}},
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "error": "not allowed" }`, "", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			senderCreator := func(protocol string, timeOut time.Duration, debug bool) (HTTPSender, error) {
+				request, err := ParseHTTPRequest(protocol)
+				if err != nil {
+					t.Errorf("invalid GraphQL request: %v", err)
+					return nil, err
+				}
+				body := map[string]interface{}{}
+				b, _ := ioutil.ReadAll(request.Body)
+				json.Unmarshal(b, &body)
+				if request.Method != "POST" || request.URL.String() != tt.givenGraphQL.URL || !reflect.DeepEqual(body, tt.thenWantRequestBody) {
+					t.Errorf("unexpected GraphQL request %s %s '%s'", request.Method, request.URL, b)
+				}
+				return NewMockHTTPSender(http.Response{Status: "200 OK", StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: ioutil.NopCloser(bytes.NewBufferString(tt.givenHTTPResponse))}), nil
+			}
+			requestTemplate, err := tt.givenGraphQL.requestTemplate()
+			if err != nil {
+				t.Errorf("GraphQL.requestTemplate() error = %v", err)
+				return
+			}
+			ct, err := newCeHTTPClientTransformer(Config{SenderCreator: senderCreator, Steps: []Step{{Name: "graphql", RequestTemplate: requestTemplate}}, ResponseTemplate: tt.givenCeTemplate,
+				Timeout: time.Second, JSONBody: true, Debug: true, GraphQL: &tt.givenGraphQL})
+			if err != nil {
+				t.Errorf("cehttpclienttransformer error = %v", err)
+				return
+			}
+			outgoingCe, err := ct.TransformEvent(&tt.whenIncomingEvent)
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("cehttpclienttransformer.TransformEvent error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if err == nil {
+				cetransformer.CompareEvents(t, "cehttpclienttransformer.TransformEvent", *outgoingCe, tt.thenWantOutgoingEvent)
+			}
+		})
+	}
+}
@@ -1,12 +1,10 @@
 package cehttpclienttransformer
 
 import (
-	"bufio"
-	"io/ioutil"
-	"log"
 	"net/http"
-	"strings"
 	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
 )
 
 // HTTPProtocolSender bla
@@ -25,24 +23,24 @@ func NewHTTPProtocolSender(protocol string, timeout time.Duration, debug bool) (
 	}
 
 	if debug {
-		log.Printf("HTTP Request String:\n%s\n", protocol)
+		logging.DebugPayload("HTTP request string", []byte(protocol))
 	}
-	buf := bufio.NewReader(strings.NewReader(protocol))
-	request, err := http.ReadRequest(buf)
+	request, err := ParseHTTPRequest(protocol)
 	if err != nil {
 		return nil, err
 	}
-	reqStr := strings.Split(protocol, "\n\n")
-	if len(reqStr) > 1 {
-		if debug {
-			log.Printf("HTTP Request Body:\n%s\n", reqStr[1])
-		}
-		request.Body = ioutil.NopCloser(strings.NewReader(reqStr[1]))
+	if debug {
+		logging.Debugf("HTTP request: %s %s, content length: %d", request.Method, request.URL, request.ContentLength)
 	}
 	hps.request = request
 	return hps, nil
 }
 
+// Request the parsed request
+func (hps *HTTPProtocolSender) Request() *http.Request {
+	return hps.request
+}
+
 // Send bla
 func (hps *HTTPProtocolSender) Send() (*http.Response, error) {
 	hps.request.RequestURI = ""
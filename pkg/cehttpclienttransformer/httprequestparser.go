@@ -0,0 +1,205 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	"gopkg.in/yaml.v2"
+)
+
+// StructuredRequest json or yaml description of a HTTP request
+type StructuredRequest struct {
+	Method  string            `json:"method" yaml:"method"`
+	URL     string            `json:"url" yaml:"url"`
+	Query   map[string]string `json:"query,omitempty" yaml:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Body raw request body
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// JSON request body which is json encoded, content-type defaults to application/json
+	JSON interface{} `json:"json,omitempty" yaml:"json,omitempty"`
+}
+
+var structuredRequestStart = regexp.MustCompile(`^(method|url|query|headers|body|json)\s*:`)
+
+// ParseHTTPRequest parses a HTTP request either in form of RFC2616 or as a structured json or yaml description.
+// The RFC2616 form accepts LF and CRLF line endings, the HTTP version in the request line is optional.
+func ParseHTTPRequest(protocol string) (*http.Request, error) {
+	trimmed := strings.TrimLeft(protocol, " \t\r\n")
+	if strings.HasPrefix(trimmed, "{") {
+		structured := StructuredRequest{}
+		if err := json.Unmarshal([]byte(trimmed), &structured); err != nil {
+			return nil, fmt.Errorf("invalid json request description: %v", err)
+		}
+		return structured.Request()
+	}
+	if structuredRequestStart.MatchString(trimmed) {
+		structured := StructuredRequest{}
+		if err := yaml.Unmarshal([]byte(trimmed), &structured); err != nil {
+			return nil, fmt.Errorf("invalid yaml request description: %v", err)
+		}
+		structured.JSON = transformer.JSONCompatible(structured.JSON)
+		return structured.Request()
+	}
+	return parseRawRequest(protocol)
+}
+
+// Request creates the described HTTP request
+func (sr StructuredRequest) Request() (*http.Request, error) {
+	if sr.Method == "" {
+		sr.Method = http.MethodGet
+	}
+	if sr.URL == "" {
+		return nil, fmt.Errorf("request description has no url")
+	}
+	body := []byte(sr.Body)
+	if sr.JSON != nil {
+		if sr.Body != "" {
+			return nil, fmt.Errorf("request description can't have a body and json")
+		}
+		var err error
+		if body, err = json.Marshal(sr.JSON); err != nil {
+			return nil, fmt.Errorf("can't encode json body: %v", err)
+		}
+	}
+	u, err := url.Parse(sr.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url '%s': %v", sr.URL, err)
+	}
+	if len(sr.Query) > 0 {
+		query := u.Query()
+		for k, v := range sr.Query {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+	}
+	request, err := newRequest(strings.ToUpper(sr.Method), u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range sr.Headers {
+		setHeader(request, k, v)
+	}
+	if sr.JSON != nil && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	return request, nil
+}
+
+func parseRawRequest(protocol string) (*http.Request, error) {
+	lineNumber := 0
+	rest := protocol
+	nextLine := func() (string, bool) {
+		if rest == "" {
+			return "", false
+		}
+		lineNumber++
+		i := strings.Index(rest, "\n")
+		var line string
+		if i < 0 {
+			line, rest = rest, ""
+		} else {
+			line, rest = rest[:i], rest[i+1:]
+		}
+		return strings.TrimSuffix(line, "\r"), true
+	}
+
+	requestLine, ok := nextLine()
+	for ok && strings.TrimSpace(requestLine) == "" {
+		requestLine, ok = nextLine()
+	}
+	if !ok {
+		return nil, fmt.Errorf("empty request")
+	}
+	method, target, err := parseRequestLine(requestLine)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v: '%s'", lineNumber, err, requestLine)
+	}
+
+	header := http.Header{}
+	for {
+		line, ok := nextLine()
+		if !ok || line == "" {
+			break
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 || strings.ContainsAny(line[:i], " \t") {
+			return nil, fmt.Errorf("line %d: invalid header, expected 'name: value': '%s'", lineNumber, line)
+		}
+		header.Add(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+	}
+
+	body := []byte(rest)
+	if strings.EqualFold(header.Get("Transfer-Encoding"), "chunked") {
+		if body, err = ioutil.ReadAll(httputil.NewChunkedReader(bytes.NewReader(body))); err != nil {
+			return nil, fmt.Errorf("line %d: invalid chunked body: %v", lineNumber+1, err)
+		}
+		header.Del("Transfer-Encoding")
+	}
+	if !strings.Contains(target, "://") {
+		host := header.Get("Host")
+		if host == "" {
+			return nil, fmt.Errorf("line 1: request target '%s' is not an absolute url and there is no host header", target)
+		}
+		target = "http://" + host + target
+	}
+	request, err := newRequest(method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("line 1: %v", err)
+	}
+	for k, values := range header {
+		for _, v := range values {
+			setHeader(request, k, v)
+		}
+	}
+	return request, nil
+}
+
+var methodToken = regexp.MustCompile("^[A-Z]+$")
+
+// parseRequestLine accepts "METHOD URL HTTP/x.y" and the short form "METHOD URL"
+func parseRequestLine(line string) (string, string, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return "", "", fmt.Errorf("invalid request line, expected 'METHOD URL [HTTP-VERSION]'")
+	}
+	if !methodToken.MatchString(fields[0]) {
+		return "", "", fmt.Errorf("invalid method '%s'", fields[0])
+	}
+	if len(fields) == 3 {
+		if _, _, ok := http.ParseHTTPVersion(fields[2]); !ok {
+			return "", "", fmt.Errorf("invalid HTTP version '%s'", fields[2])
+		}
+	}
+	return fields[0], fields[1], nil
+}
+
+func newRequest(method, target string, body []byte) (*http.Request, error) {
+	request, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		request.Body = http.NoBody
+	}
+	request.ContentLength = int64(len(body))
+	return request, nil
+}
+
+func setHeader(request *http.Request, key, value string) {
+	switch http.CanonicalHeaderKey(key) {
+	case "Host":
+		request.Host = value
+	case "Content-Length":
+		// always computed from the actual body
+	default:
+		request.Header.Add(key, value)
+	}
+}
@@ -0,0 +1,93 @@
+package cehttpclienttransformer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestParseHTTPRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		whenProtocol   string
+		thenWantErr    string
+		thenWantMethod string
+		thenWantURL    string
+		thenWantHeader http.Header
+		thenWantBody   string
+	}{
+		{name: "LF",
+			whenProtocol:   "POST http://localhost:8080/person HTTP/1.1\ncontent-type: application/json\n\n{ \"name\": \"Alex\" }",
+			thenWantMethod: "POST", thenWantURL: "http://localhost:8080/person",
+			thenWantHeader: http.Header{"Content-Type": {"application/json"}},
+			thenWantBody:   `{ "name": "Alex" }`},
+		{name: "CRLF",
+			whenProtocol:   "POST http://localhost:8080/person HTTP/1.1\r\ncontent-type: application/json\r\n\r\n{ \"name\": \"Alex\" }",
+			thenWantMethod: "POST", thenWantURL: "http://localhost:8080/person",
+			thenWantHeader: http.Header{"Content-Type": {"application/json"}},
+			thenWantBody:   `{ "name": "Alex" }`},
+		{name: "body with blank lines and wrong content-length",
+			whenProtocol:   "POST http://localhost:8080/text\ncontent-length: 3\n\nfirst\n\nsecond",
+			thenWantMethod: "POST", thenWantURL: "http://localhost:8080/text",
+			thenWantHeader: http.Header{},
+			thenWantBody:   "first\n\nsecond"},
+		{name: "short form",
+			whenProtocol:   "\nGET https://x/y",
+			thenWantMethod: "GET", thenWantURL: "https://x/y",
+			thenWantHeader: http.Header{}},
+		{name: "host header",
+			whenProtocol:   "GET /path HTTP/1.1\nHost: localhost:8080\n\n",
+			thenWantMethod: "GET", thenWantURL: "http://localhost:8080/path",
+			thenWantHeader: http.Header{}},
+		{name: "chunked",
+			whenProtocol:   "POST http://localhost:8080/chunked HTTP/1.1\nTransfer-Encoding: chunked\n\n5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n",
+			thenWantMethod: "POST", thenWantURL: "http://localhost:8080/chunked",
+			thenWantHeader: http.Header{},
+			thenWantBody:   "hello world"},
+		{name: "json description",
+			whenProtocol:   `{ "method": "post", "url": "http://localhost:8080/person", "query": { "q": "a b" }, "json": { "name": "Alex" } }`,
+			thenWantMethod: "POST", thenWantURL: "http://localhost:8080/person?q=a+b",
+			thenWantHeader: http.Header{"Content-Type": {"application/json"}},
+			thenWantBody:   `{"name":"Alex"}`},
+		{name: "yaml description",
+			whenProtocol:   "method: PUT\nurl: http://localhost:8080/person\nheaders:\n  x-tenant: a\njson:\n  name: Alex\n  tags: [a, b]\n",
+			thenWantMethod: "PUT", thenWantURL: "http://localhost:8080/person",
+			thenWantHeader: http.Header{"X-Tenant": {"a"}, "Content-Type": {"application/json"}},
+			thenWantBody:   `{"name":"Alex","tags":["a","b"]}`},
+		{name: "invalid method", whenProtocol: "get http://localhost/ HTTP/1.1\n\n", thenWantErr: "line 1: invalid method 'get': 'get http://localhost/ HTTP/1.1'"},
+		{name: "invalid header", whenProtocol: "GET http://localhost/ HTTP/1.1\naccept application/json\n\n", thenWantErr: "line 2: invalid header, expected 'name: value': 'accept application/json'"},
+		{name: "invalid version", whenProtocol: "GET http://localhost/ HTTP/x\n\n", thenWantErr: "line 1: invalid HTTP version 'HTTP/x': 'GET http://localhost/ HTTP/x'"},
+		{name: "empty", whenProtocol: "\n\n", thenWantErr: "empty request"},
+		{name: "json description without url", whenProtocol: `{ "method": "GET" }`, thenWantErr: "request description has no url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := ParseHTTPRequest(tt.whenProtocol)
+			if tt.thenWantErr != "" {
+				if err == nil || err.Error() != tt.thenWantErr {
+					t.Errorf("ParseHTTPRequest() error = '%v', want '%s'", err, tt.thenWantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ParseHTTPRequest() error = %v", err)
+				return
+			}
+			if request.Method != tt.thenWantMethod || request.URL.String() != tt.thenWantURL {
+				t.Errorf("ParseHTTPRequest() = %s %s, want %s %s", request.Method, request.URL, tt.thenWantMethod, tt.thenWantURL)
+			}
+			for k := range tt.thenWantHeader {
+				if request.Header.Get(k) != tt.thenWantHeader.Get(k) {
+					t.Errorf("ParseHTTPRequest() header %s = '%s', want '%s'", k, request.Header.Get(k), tt.thenWantHeader.Get(k))
+				}
+			}
+			body, _ := ioutil.ReadAll(request.Body)
+			if string(body) != tt.thenWantBody {
+				t.Errorf("ParseHTTPRequest() body = '%s', want '%s'", body, tt.thenWantBody)
+			}
+			if request.ContentLength != int64(len(tt.thenWantBody)) {
+				t.Errorf("ParseHTTPRequest() content length = %d, want %d", request.ContentLength, len(tt.thenWantBody))
+			}
+		})
+	}
+}
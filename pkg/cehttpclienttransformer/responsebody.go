@@ -0,0 +1,132 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// DecodeBody decodes a response body according to its content type:
+// json to a structure, xml to a map, form-encoded to a map, csv to a list of records, text to a string and binary content to a base64 string.
+// Without content type json is tried and text is the fallback.
+func DecodeBody(contentType string, body []byte) (interface{}, error) {
+	mediaType := ""
+	if contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+		}
+	}
+	switch {
+	case mediaType == "":
+		if data, err := decodeJSON(body); err == nil {
+			return data, nil
+		}
+		return string(body), nil
+	case mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON(body)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return decodeXML(body)
+	case mediaType == "application/x-www-form-urlencoded":
+		return decodeForm(body)
+	case mediaType == "text/csv":
+		return csv.NewReader(bytes.NewReader(body)).ReadAll()
+	case strings.HasPrefix(mediaType, "text/"):
+		return string(body), nil
+	default:
+		return base64.StdEncoding.EncodeToString(body), nil
+	}
+}
+
+func decodeJSON(body []byte) (interface{}, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func decodeForm(body []byte) (interface{}, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	form := map[string]interface{}{}
+	for k, v := range values {
+		if len(v) == 1 {
+			form[k] = v[0]
+		} else {
+			form[k] = v
+		}
+	}
+	return form, nil
+}
+
+// decodeXML converts a xml document to a map. Attributes are available as "-name", the character data of elements with attributes or children as "#text".
+// Repeated elements are collected in a list.
+func decodeXML(body []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		element["-"+attr.Name.Local] = attr.Value
+	}
+	text := strings.Builder{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch existing := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(existing, child)
+			default:
+				element[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				element["#text"] = content
+			}
+			return element, nil
+		}
+	}
+}
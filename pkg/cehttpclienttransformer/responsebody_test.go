@@ -0,0 +1,41 @@
+package cehttpclienttransformer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name             string
+		givenContentType string
+		whenBody         string
+		thenWant         interface{}
+		thenWantErr      bool
+	}{
+		{name: "json", givenContentType: "application/json", whenBody: `{ "name": "Alex" }`, thenWant: map[string]interface{}{"name": "Alex"}},
+		{name: "json suffix", givenContentType: "application/problem+json", whenBody: `{ "title": "bad" }`, thenWant: map[string]interface{}{"title": "bad"}},
+		{name: "no content type json", whenBody: `["a"]`, thenWant: []interface{}{"a"}},
+		{name: "no content type text", whenBody: `hello`, thenWant: "hello"},
+		{name: "xml", givenContentType: "text/xml; charset=utf-8",
+			whenBody: `<Envelope><Body><Person id="1"><Name>Alex</Name><Tag>a</Tag><Tag>b</Tag></Person></Body></Envelope>`,
+			thenWant: map[string]interface{}{"Envelope": map[string]interface{}{"Body": map[string]interface{}{"Person": map[string]interface{}{"-id": "1", "Name": "Alex", "Tag": []interface{}{"a", "b"}}}}}},
+		{name: "invalid xml", givenContentType: "application/xml", whenBody: `<a><b></a>`, thenWantErr: true},
+		{name: "form", givenContentType: "application/x-www-form-urlencoded", whenBody: `name=Alex&tag=a&tag=b`, thenWant: map[string]interface{}{"name": "Alex", "tag": []string{"a", "b"}}},
+		{name: "csv", givenContentType: "text/csv", whenBody: "name,age\nAlex,42\n", thenWant: [][]string{{"name", "age"}, {"Alex", "42"}}},
+		{name: "text", givenContentType: "text/html", whenBody: `<p>hi</p>`, thenWant: "<p>hi</p>"},
+		{name: "binary", givenContentType: "application/octet-stream", whenBody: "\x00\x01", thenWant: "AAE="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeBody(tt.givenContentType, []byte(tt.whenBody))
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("DecodeBody() error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if !tt.thenWantErr && !reflect.DeepEqual(got, tt.thenWant) {
+				t.Errorf("DecodeBody() = %#v, want %#v", got, tt.thenWant)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+)
+
+// CacheStats hit and miss counters of a ResponseCache
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Revalidated uint64
+	Entries     int
+}
+
+// ResponseCache in-memory LRU cache for HTTP responses, keyed by the rendered request
+type ResponseCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	headers []string
+	entries map[string]*list.Element
+	lru     *list.List
+	stats   CacheStats
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key        string
+	status     string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+	etag       string
+	expires    time.Time
+}
+
+// NewResponseCache creates a cache holding at most size responses for ttl. headers are the request headers which are part of the cache key
+func NewResponseCache(size int, ttl time.Duration, headers []string) *ResponseCache {
+	rc := new(ResponseCache)
+	rc.size = size
+	rc.ttl = ttl
+	for _, h := range headers {
+		if h = strings.TrimSpace(h); h != "" {
+			rc.headers = append(rc.headers, http.CanonicalHeaderKey(h))
+		}
+	}
+	rc.entries = map[string]*list.Element{}
+	rc.lru = list.New()
+	rc.now = time.Now
+	return rc
+}
+
+// Stats returns a snapshot of the cache counters
+func (rc *ResponseCache) Stats() CacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	stats := rc.stats
+	stats.Entries = rc.lru.Len()
+	return stats
+}
+
+// Key builds the cache key from method, url, selected headers and a hash of the body. The request body is restored after reading.
+func (rc *ResponseCache) Key(request *http.Request) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(request.Method)
+	sb.WriteString(" ")
+	sb.WriteString(request.URL.String())
+	for _, h := range rc.headers {
+		sb.WriteString("\n")
+		sb.WriteString(h)
+		sb.WriteString(": ")
+		sb.WriteString(strings.Join(request.Header.Values(h), ","))
+	}
+	if request.Body != nil {
+		body, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			return "", err
+		}
+		request.Body.Close()
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		sb.WriteString("\n")
+		sb.WriteString(hex.EncodeToString(hash[:]))
+	}
+	return sb.String(), nil
+}
+
+func (rc *ResponseCache) lookup(key string) (*cacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	elem, ok := rc.entries[key]
+	if !ok {
+		rc.stats.Misses++
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if rc.now().Before(entry.expires) {
+		rc.lru.MoveToFront(elem)
+		rc.stats.Hits++
+		return entry, true
+	}
+	if entry.etag == "" {
+		rc.removeElement(elem)
+		rc.stats.Misses++
+		return nil, false
+	}
+	// stale, but can be revalidated with the ETag
+	rc.stats.Misses++
+	return entry, false
+}
+
+func (rc *ResponseCache) store(entry *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.storeLocked(entry)
+}
+
+// storeLocked the lock must be held
+func (rc *ResponseCache) storeLocked(entry *cacheEntry) {
+	if elem, ok := rc.entries[entry.key]; ok {
+		elem.Value = entry
+		rc.lru.MoveToFront(elem)
+		return
+	}
+	rc.entries[entry.key] = rc.lru.PushFront(entry)
+	for rc.lru.Len() > rc.size {
+		rc.removeElement(rc.lru.Back())
+	}
+}
+
+// revalidated the entry is fresh again, expires is written under the lock because lookup reads it
+func (rc *ResponseCache) revalidated(entry *cacheEntry, header http.Header) {
+	ttl, ok := rc.responseTTL(header)
+	if !ok {
+		ttl = rc.ttl
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.stats.Revalidated++
+	entry.expires = rc.now().Add(ttl)
+	rc.storeLocked(entry)
+}
+
+func (rc *ResponseCache) remove(key string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if elem, ok := rc.entries[key]; ok {
+		rc.removeElement(elem)
+	}
+}
+
+func (rc *ResponseCache) removeElement(elem *list.Element) {
+	rc.lru.Remove(elem)
+	delete(rc.entries, elem.Value.(*cacheEntry).key)
+}
+
+// responseTTL evaluates the Cache-Control header of a response, false means the response must not be cached
+func (rc *ResponseCache) responseTTL(header http.Header) (time.Duration, bool) {
+	ttl := rc.ttl
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store", directive == "private":
+			return 0, false
+		case directive == "no-cache":
+			ttl = 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil && time.Duration(seconds)*time.Second < ttl {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return ttl, true
+}
+
+func (entry *cacheEntry) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        entry.status,
+		StatusCode:    entry.statusCode,
+		Proto:         entry.proto,
+		Header:        entry.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       request,
+	}
+}
+
+// CachingSender HTTPSender which answers from a ResponseCache if possible
+type CachingSender struct {
+	cache   *ResponseCache
+	request *http.Request
+	sender  HTTPSender
+	debug   bool
+}
+
+// NewCachingSender wraps sender, request is the request the sender is going to send
+func NewCachingSender(cache *ResponseCache, request *http.Request, sender HTTPSender, debug bool) *CachingSender {
+	return &CachingSender{cache: cache, request: request, sender: sender, debug: debug}
+}
+
+// Request the request which is sent or answered from the cache
+func (cs *CachingSender) Request() *http.Request {
+	return cs.request
+}
+
+// Send answers with the cached response or delegates to the wrapped sender
+func (cs *CachingSender) Send() (*http.Response, error) {
+	if cs.request.Method != http.MethodGet && cs.request.Method != http.MethodHead && cs.request.Method != http.MethodPost {
+		return cs.sender.Send()
+	}
+	key, err := cs.cache.Key(cs.request)
+	if err != nil {
+		return nil, err
+	}
+	entry, fresh := cs.cache.lookup(key)
+	if fresh {
+		if cs.debug {
+			logging.Debugf("HTTP response cache hit: %s %s", cs.request.Method, cs.request.URL)
+		}
+		return entry.response(cs.request), nil
+	}
+	if entry != nil {
+		cs.request.Header.Set("If-None-Match", entry.etag)
+	}
+	response, err := cs.sender.Send()
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && response.StatusCode == http.StatusNotModified {
+		response.Body.Close()
+		if cs.debug {
+			logging.Debugf("HTTP response cache revalidated: %s %s", cs.request.Method, cs.request.URL)
+		}
+		cs.cache.revalidated(entry, response.Header)
+		return entry.response(cs.request), nil
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response, nil
+	}
+	ttl, cacheable := cs.cache.responseTTL(response.Header)
+	etag := response.Header.Get("ETag")
+	if !cacheable || (ttl <= 0 && etag == "") {
+		cs.cache.remove(key)
+		return response, nil
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	entry = &cacheEntry{key: key, status: response.Status, statusCode: response.StatusCode, proto: response.Proto,
+		header: response.Header.Clone(), body: body, etag: etag, expires: cs.cache.now().Add(ttl)}
+	cs.cache.store(entry)
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return response, nil
+}
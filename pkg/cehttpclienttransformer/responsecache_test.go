@@ -0,0 +1,149 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type CountingHTTPSender struct {
+	request   *http.Request
+	responses []http.Response
+	calls     int
+}
+
+func (cs *CountingHTTPSender) Request() *http.Request {
+	return cs.request
+}
+
+func (cs *CountingHTTPSender) Send() (*http.Response, error) {
+	resp := cs.responses[cs.calls]
+	cs.calls++
+	resp.Body = ioutil.NopCloser(bytes.NewBufferString("body"))
+	return &resp, nil
+}
+
+func TestCachingSender_Send(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenTTL       time.Duration
+		givenResponses []http.Response
+		givenAdvance   time.Duration
+		whenSends      int
+		thenWantCalls  int
+		thenWantStats  CacheStats
+	}{
+		{name: "hit",
+			givenTTL:       time.Minute,
+			givenResponses: []http.Response{{Status: "200 OK", StatusCode: 200, Header: http.Header{}}},
+			whenSends:      3,
+			thenWantCalls:  1,
+			thenWantStats:  CacheStats{Hits: 2, Misses: 1, Entries: 1}},
+		{name: "no-store",
+			givenTTL: time.Minute,
+			givenResponses: []http.Response{{Status: "200 OK", StatusCode: 200, Header: http.Header{"Cache-Control": {"no-store"}}},
+				{Status: "200 OK", StatusCode: 200, Header: http.Header{"Cache-Control": {"no-store"}}}},
+			whenSends:     2,
+			thenWantCalls: 2,
+			thenWantStats: CacheStats{Misses: 2}},
+		{name: "error status not cached",
+			givenTTL: time.Minute,
+			givenResponses: []http.Response{{Status: "500 Internal Server Error", StatusCode: 500, Header: http.Header{}},
+				{Status: "200 OK", StatusCode: 200, Header: http.Header{}}},
+			whenSends:     2,
+			thenWantCalls: 2,
+			thenWantStats: CacheStats{Misses: 2, Entries: 1}},
+		{name: "expired",
+			givenTTL: time.Minute,
+			givenResponses: []http.Response{{Status: "200 OK", StatusCode: 200, Header: http.Header{"Cache-Control": {"max-age=1"}}},
+				{Status: "200 OK", StatusCode: 200, Header: http.Header{}}},
+			givenAdvance:  2 * time.Second,
+			whenSends:     2,
+			thenWantCalls: 2,
+			thenWantStats: CacheStats{Misses: 2, Entries: 1}},
+		{name: "revalidated with etag",
+			givenTTL: time.Minute,
+			givenResponses: []http.Response{{Status: "200 OK", StatusCode: 200, Header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}},
+				{Status: "304 Not Modified", StatusCode: 304, Header: http.Header{}}},
+			whenSends:     2,
+			thenWantCalls: 2,
+			thenWantStats: CacheStats{Misses: 2, Revalidated: 1, Entries: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			cache := NewResponseCache(10, tt.givenTTL, []string{"authorization"})
+			cache.now = func() time.Time { return now }
+			request, _ := http.NewRequest("GET", "http://localhost:8080/customer/1", nil)
+			sender := &CountingHTTPSender{request: request, responses: tt.givenResponses}
+			for i := 0; i < tt.whenSends; i++ {
+				response, err := NewCachingSender(cache, request, sender, true).Send()
+				if err != nil {
+					t.Errorf("CachingSender.Send() error = %v", err)
+					return
+				}
+				body, _ := ioutil.ReadAll(response.Body)
+				if response.StatusCode == 200 && string(body) != "body" {
+					t.Errorf("CachingSender.Send() body = '%s', want 'body'", body)
+				}
+				now = now.Add(tt.givenAdvance)
+			}
+			if sender.calls != tt.thenWantCalls {
+				t.Errorf("CachingSender.Send() calls = %v, want %v", sender.calls, tt.thenWantCalls)
+			}
+			if cache.Stats() != tt.thenWantStats {
+				t.Errorf("ResponseCache.Stats() = %+v, want %+v", cache.Stats(), tt.thenWantStats)
+			}
+		})
+	}
+}
+
+func TestResponseCache_Key(t *testing.T) {
+	cache := NewResponseCache(2, time.Minute, []string{"X-Tenant"})
+	req1, _ := http.NewRequest("POST", "http://localhost/lookup", bytes.NewBufferString(`{"id":1}`))
+	req1.Header.Set("X-Tenant", "a")
+	req2, _ := http.NewRequest("POST", "http://localhost/lookup", bytes.NewBufferString(`{"id":1}`))
+	req2.Header.Set("X-Tenant", "b")
+	key1, _ := cache.Key(req1)
+	key2, _ := cache.Key(req2)
+	if key1 == key2 {
+		t.Errorf("ResponseCache.Key() must differ for different selected headers")
+	}
+	body, _ := ioutil.ReadAll(req1.Body)
+	if string(body) != `{"id":1}` {
+		t.Errorf("ResponseCache.Key() must restore the request body, actual = '%s'", body)
+	}
+}
+
+func TestResponseCache_revalidatedConcurrently(t *testing.T) {
+	cache := NewResponseCache(10, time.Minute, nil)
+	entry := &cacheEntry{key: "key", etag: `"v1"`, header: http.Header{}}
+	cache.store(entry)
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			for i := 0; i < 1000; i++ {
+				cache.revalidated(entry, http.Header{})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			for i := 0; i < 1000; i++ {
+				cache.lookup("key")
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if _, ok := cache.lookup("key"); !ok {
+		t.Error("ResponseCache.lookup() revalidated entry is not fresh")
+	}
+}
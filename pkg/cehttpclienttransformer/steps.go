@@ -0,0 +1,263 @@
+package cehttpclienttransformer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+	"github.com/alitari/ce-go-template/pkg/transformer"
+)
+
+// Step a named HTTP request of a chain. The request template sees the incoming event and the responses of the previous steps under `steps.<name>`
+type Step struct {
+	Name            string `json:"name" yaml:"name"`
+	RequestTemplate string `json:"request" yaml:"request"`
+	// Condition optional template, the step is skipped if it doesn't resolve to "true"
+	Condition string `json:"condition,omitempty" yaml:"condition"`
+	// Parallel requests which are sent concurrently instead of RequestTemplate, the responses are available under `steps.<name>.<request name>`
+	Parallel []ParallelRequest `json:"parallel,omitempty" yaml:"parallel"`
+	// Timeout overall timeout for the parallel requests, e.g. "500ms". Defaults to the HTTP timeout
+	Timeout string `json:"timeout,omitempty" yaml:"timeout"`
+}
+
+// ParallelRequest a named HTTP request of a parallel step
+type ParallelRequest struct {
+	Name            string `json:"name" yaml:"name"`
+	RequestTemplate string `json:"request" yaml:"request"`
+	// Optional a failed optional request doesn't fail the step, its response is replaced by `{ "error": "<message>" }`
+	Optional bool `json:"optional,omitempty" yaml:"optional"`
+}
+
+// ParseSteps parses a json array of steps
+func ParseSteps(stepsJSON string) ([]Step, error) {
+	steps := []Step{}
+	if err := json.Unmarshal([]byte(stepsJSON), &steps); err != nil {
+		return nil, fmt.Errorf("can't parse steps: %v", err)
+	}
+	return steps, nil
+}
+
+type step struct {
+	name      string
+	request   *transformer.Transformer
+	condition *transformer.Transformer
+	parallel  []*parallelRequest
+	timeout   time.Duration
+}
+
+type parallelRequest struct {
+	name     string
+	request  *transformer.Transformer
+	optional bool
+}
+
+type parallelResult struct {
+	name     string
+	response map[string]interface{}
+	err      error
+}
+
+func newSteps(steps []Step, debug bool) ([]*step, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("at least one step is required")
+	}
+	names := map[string]bool{}
+	result := []*step{}
+	for i, s := range steps {
+		if s.Name == "" {
+			return nil, fmt.Errorf("step %d has no name", i)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("step name '%s' is not unique", s.Name)
+		}
+		names[s.Name] = true
+		st := &step{name: s.Name}
+		if len(s.Parallel) > 0 {
+			if s.RequestTemplate != "" {
+				return nil, fmt.Errorf("step '%s' can't have a request and parallel requests", s.Name)
+			}
+			if err := st.newParallelRequests(s, debug); err != nil {
+				return nil, err
+			}
+		} else {
+			request, err := transformer.NewTransformer(s.RequestTemplate, nil, debug)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s': %v", s.Name, err)
+			}
+			st.request = request
+		}
+		if s.Condition != "" {
+			condition, err := transformer.NewTransformer(s.Condition, nil, debug)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s' condition: %v", s.Name, err)
+			}
+			st.condition = condition
+		}
+		result = append(result, st)
+	}
+	return result, nil
+}
+
+func (st *step) newParallelRequests(s Step, debug bool) error {
+	if s.Timeout != "" {
+		timeout, err := time.ParseDuration(s.Timeout)
+		if err != nil {
+			return fmt.Errorf("step '%s' timeout: %v", s.Name, err)
+		}
+		st.timeout = timeout
+	}
+	names := map[string]bool{}
+	for i, p := range s.Parallel {
+		if p.Name == "" {
+			return fmt.Errorf("step '%s': parallel request %d has no name", s.Name, i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("step '%s': parallel request name '%s' is not unique", s.Name, p.Name)
+		}
+		names[p.Name] = true
+		request, err := transformer.NewTransformer(p.RequestTemplate, nil, debug)
+		if err != nil {
+			return fmt.Errorf("step '%s' parallel request '%s': %v", s.Name, p.Name, err)
+		}
+		st.parallel = append(st.parallel, &parallelRequest{name: p.Name, request: request, optional: p.Optional})
+	}
+	return nil
+}
+
+// stepInput the event data extended with the responses of the steps executed so far
+func stepInput(inputEventData map[string]interface{}, stepResponses map[string]interface{}) map[string]interface{} {
+	input := map[string]interface{}{}
+	for k, v := range inputEventData {
+		input[k] = v
+	}
+	input["steps"] = stepResponses
+	return input
+}
+
+// runSteps executes the steps in order and returns the responses by step name and the response of the last executed step. The response of a parallel step is the map of its responses by request name
+func (ct *CeHTTPClientTransformer) runSteps(inputEventData map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	stepResponses := map[string]interface{}{}
+	var lastResponse map[string]interface{}
+	for _, s := range ct.steps {
+		input := stepInput(inputEventData, stepResponses)
+		if s.condition != nil {
+			conditionBytes, err := s.condition.TransformInputToBytes(input)
+			if err != nil {
+				return nil, nil, fmt.Errorf("step '%s' condition: %v", s.name, err)
+			}
+			if string(conditionBytes) != "true" {
+				if ct.config.Debug {
+					logging.Debugf("skipping step '%s', condition is '%s'", s.name, conditionBytes)
+				}
+				continue
+			}
+		}
+		if len(s.parallel) > 0 {
+			respData, err := ct.runParallel(s, input)
+			if err != nil {
+				return nil, nil, fmt.Errorf("step '%s': %v", s.name, err)
+			}
+			stepResponses[s.name] = respData
+			lastResponse = respData
+			continue
+		}
+		httpBytes, err := s.request.TransformInputToBytes(input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("step '%s': %v", s.name, err)
+		}
+		respData, err := ct.send(context.Background(), input, string(httpBytes))
+		if err != nil {
+			return nil, nil, fmt.Errorf("step '%s': %v", s.name, err)
+		}
+		stepResponses[s.name] = respData
+		lastResponse = respData
+	}
+	return stepResponses, lastResponse, nil
+}
+
+// runParallel sends the parallel requests of a step concurrently and waits until all responses are received, a required request failed or the step timeout is over. Then the pending requests are cancelled
+func (ct *CeHTTPClientTransformer) runParallel(s *step, input map[string]interface{}) (map[string]interface{}, error) {
+	protocols := make([]string, len(s.parallel))
+	for i, p := range s.parallel {
+		httpBytes, err := p.request.TransformInputToBytes(input)
+		if err != nil {
+			return nil, fmt.Errorf("parallel request '%s': %v", p.name, err)
+		}
+		protocols[i] = string(httpBytes)
+	}
+	timeout := s.timeout
+	if timeout == 0 {
+		timeout = ct.config.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	results := make(chan parallelResult, len(s.parallel))
+	optional := map[string]bool{}
+	for i, p := range s.parallel {
+		optional[p.name] = p.optional
+		go func(name, protocol string) {
+			response, err := ct.send(ctx, input, protocol)
+			results <- parallelResult{name: name, response: response, err: err}
+		}(p.name, protocols[i])
+	}
+	received := map[string]parallelResult{}
+	for len(received) < len(s.parallel) {
+		select {
+		case result := <-results:
+			if result.err != nil && !optional[result.name] {
+				return nil, fmt.Errorf("parallel request '%s': %v", result.name, result.err)
+			}
+			received[result.name] = result
+		case <-ctx.Done():
+			return collectParallel(s, received, fmt.Errorf("timeout after %v", timeout))
+		}
+	}
+	return collectParallel(s, received, nil)
+}
+
+func collectParallel(s *step, received map[string]parallelResult, timeoutErr error) (map[string]interface{}, error) {
+	responses := map[string]interface{}{}
+	for _, p := range s.parallel {
+		result, ok := received[p.name]
+		err := result.err
+		if !ok {
+			err = timeoutErr
+		}
+		if err != nil {
+			if !p.optional {
+				return nil, fmt.Errorf("parallel request '%s': %v", p.name, err)
+			}
+			responses[p.name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+		responses[p.name] = result.response
+	}
+	return responses, nil
+}
+
+// send the request rendered from the input, with a rate limiter only if a token of the key of the input is available within the timeout. The request is cancelled with the context
+func (ct *CeHTTPClientTransformer) send(ctx context.Context, input map[string]interface{}, protocol string) (map[string]interface{}, error) {
+	if ct.rateLimiter != nil {
+		waitCtx, cancel := context.WithTimeout(ctx, ct.config.Timeout)
+		defer cancel()
+		if err := ct.rateLimiter.Wait(waitCtx, input); err != nil {
+			return nil, err
+		}
+	}
+	sender, err := ct.config.SenderCreator(protocol, ct.config.Timeout, ct.config.Debug)
+	if err != nil {
+		return nil, err
+	}
+	if rs, ok := sender.(requestSender); ok {
+		// the wrapping senders share the request, so the context applies to all of them
+		request := rs.Request()
+		*request = *request.WithContext(ctx)
+	}
+	resp, err := sender.Send()
+	if err != nil {
+		return nil, err
+	}
+	return ResponseToMap(resp, ct.config.JSONBody)
+}
@@ -0,0 +1,239 @@
+package cehttpclienttransformer
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestTransformEventSteps(t *testing.T) {
+	tests := []struct {
+		name                  string
+		givenSteps            []Step
+		givenCeTemplate       string
+		givenHTTPResponses    map[string]string
+		whenIncomingEvent     cloudevents.Event
+		thenWantOutgoingEvent cloudevents.Event
+		thenWantErr           bool
+	}{
+		{name: "two steps",
+			givenSteps: []Step{
+				{Name: "lookup", RequestTemplate: "GET http://localhost:8080/customer?name={{ .data.name }} HTTP/1.1\n\n"},
+				{Name: "details", RequestTemplate: "GET http://localhost:8080/details/{{ .steps.lookup.body.id }} HTTP/1.1\n\n"},
+			},
+			givenHTTPResponses: map[string]string{
+				"GET http://localhost:8080/customer?name=Alex HTTP/1.1\n\n": `{ "id": "4711" }`,
+				"GET http://localhost:8080/details/4711 HTTP/1.1\n\n":       `{ "city": "Berlin" }`,
+			},
+			givenCeTemplate:       `{ "id": {{ .steps.lookup.body.id | quote }}, "city": {{ .httpresponse.body.city | quote }} }`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "name": "Alex" }`),
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "id": "4711", "city": "Berlin" }`, "", ""),
+		},
+		{name: "skipped step",
+			givenSteps: []Step{
+				{Name: "lookup", RequestTemplate: "GET http://localhost:8080/customer?name={{ .data.name }} HTTP/1.1\n\n"},
+				{Name: "details", Condition: `{{ hasKey .steps.lookup.body "id" }}`, RequestTemplate: "GET http://localhost:8080/details/{{ .steps.lookup.body.id }} HTTP/1.1\n\n"},
+			},
+			givenHTTPResponses: map[string]string{
+				"GET http://localhost:8080/customer?name=Bob HTTP/1.1\n\n": `{ }`,
+			},
+			givenCeTemplate:       `{ "found": {{ hasKey .steps "details" }} }`,
+			whenIncomingEvent:     cetransformer.NewEventWithJSONStringData(`{ "name": "Bob" }`),
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "found": false }`, "", ""),
+		},
+		{name: "duplicate step name",
+			givenSteps:      []Step{{Name: "a", RequestTemplate: "GET http://localhost:8080/ HTTP/1.1\n\n"}, {Name: "a", RequestTemplate: "GET http://localhost:8080/ HTTP/1.1\n\n"}},
+			givenCeTemplate: `{}`,
+			thenWantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			senderCreator := func(protocol string, timeOut time.Duration, debug bool) (HTTPSender, error) {
+				body, ok := tt.givenHTTPResponses[protocol]
+				if !ok {
+					t.Errorf("unexpected HTTP-Protocol, actual = '%s'", protocol)
+					return nil, errors.New("unexpected http-protocol")
+				}
+				return NewMockHTTPSender(http.Response{Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))}), nil
+			}
+			ct, err := newCeHTTPClientTransformer(Config{SenderCreator: senderCreator, Steps: tt.givenSteps, ResponseTemplate: tt.givenCeTemplate, Timeout: time.Second, JSONBody: true, Debug: true})
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("cehttpclienttransformer error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			outgoingCe, err := ct.TransformEvent(&tt.whenIncomingEvent)
+			if err != nil {
+				t.Errorf("cehttpclienttransformer.TransformEvent error = %v", err)
+				return
+			}
+			cetransformer.CompareEvents(t, "cehttpclienttransformer.TransformEvent", *outgoingCe, tt.thenWantOutgoingEvent)
+		})
+	}
+}
+
+func TestParseSteps(t *testing.T) {
+	steps, err := ParseSteps(`[{"name": "lookup", "request": "GET http://localhost/ HTTP/1.1\n\n", "condition": "true"}]`)
+	if err != nil {
+		t.Errorf("ParseSteps() error = %v", err)
+		return
+	}
+	if len(steps) != 1 || steps[0].Name != "lookup" || steps[0].Condition != "true" {
+		t.Errorf("ParseSteps() = %v", steps)
+	}
+	if _, err := ParseSteps(`{`); err == nil {
+		t.Errorf("ParseSteps() want error for invalid json")
+	}
+}
+
+type DelayedHTTPSender struct {
+	delay time.Duration
+	body  string
+	err   error
+}
+
+func (ds *DelayedHTTPSender) Send() (*http.Response, error) {
+	time.Sleep(ds.delay)
+	if ds.err != nil {
+		return nil, ds.err
+	}
+	return &http.Response{Status: "200 OK", StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(ds.body))}, nil
+}
+
+func TestTransformEventParallel(t *testing.T) {
+	parallel := []ParallelRequest{
+		{Name: "price", RequestTemplate: "GET http://localhost:8080/price/{{ .data.sku }} HTTP/1.1\n\n"},
+		{Name: "stock", RequestTemplate: "GET http://localhost:8080/stock/{{ .data.sku }} HTTP/1.1\n\n"},
+		{Name: "rating", RequestTemplate: "GET http://localhost:8080/rating/{{ .data.sku }} HTTP/1.1\n\n", Optional: true},
+	}
+	tests := []struct {
+		name                  string
+		givenSenders          map[string]*DelayedHTTPSender
+		givenCeTemplate       string
+		thenWantOutgoingEvent cloudevents.Event
+		thenWantErr           bool
+	}{
+		{name: "all responses",
+			givenSenders: map[string]*DelayedHTTPSender{
+				"price":  {delay: 30 * time.Millisecond, body: `{ "value": 12 }`},
+				"stock":  {delay: 10 * time.Millisecond, body: `{ "value": 3 }`},
+				"rating": {delay: 20 * time.Millisecond, body: `{ "value": 5 }`},
+			},
+			givenCeTemplate:       `{ "price": {{ .steps.enrich.price.body.value }}, "stock": {{ .steps.enrich.stock.body.value }}, "rating": {{ .steps.enrich.rating.body.value }} }`,
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "price": 12, "stock": 3, "rating": 5 }`, "", ""),
+		},
+		{name: "httpresponse of a parallel step",
+			givenSenders: map[string]*DelayedHTTPSender{
+				"price":  {body: `{ "value": 12 }`},
+				"stock":  {body: `{ "value": 3 }`},
+				"rating": {body: `{ "value": 5 }`},
+			},
+			givenCeTemplate:       `{ "price": {{ .httpresponse.price.body.value }}, "stock": {{ .httpresponse.stock.body.value }} }`,
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "price": 12, "stock": 3 }`, "", ""),
+		},
+		{name: "optional request times out",
+			givenSenders: map[string]*DelayedHTTPSender{
+				"price":  {body: `{ "value": 12 }`},
+				"stock":  {body: `{ "value": 3 }`},
+				"rating": {delay: time.Second, body: `{ "value": 5 }`},
+			},
+			givenCeTemplate:       `{ "price": {{ .steps.enrich.price.body.value }}, "rating": {{ .steps.enrich.rating.error | quote }} }`,
+			thenWantOutgoingEvent: cetransformer.NewEventWithJSONStringData(`{ "price": 12, "rating": "timeout after 100ms" }`, "", ""),
+		},
+		{name: "required request fails",
+			givenSenders: map[string]*DelayedHTTPSender{
+				"price":  {err: errors.New("connection refused")},
+				"stock":  {body: `{ "value": 3 }`},
+				"rating": {body: `{ "value": 5 }`},
+			},
+			givenCeTemplate: `{}`,
+			thenWantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			givenSenders := tt.givenSenders
+			senderCreator := func(protocol string, timeOut time.Duration, debug bool) (HTTPSender, error) {
+				for name, sender := range givenSenders {
+					if protocol == "GET http://localhost:8080/"+name+"/4711 HTTP/1.1\n\n" {
+						return sender, nil
+					}
+				}
+				t.Errorf("unexpected HTTP-Protocol, actual = '%s'", protocol)
+				return nil, errors.New("unexpected http-protocol")
+			}
+			steps := []Step{{Name: "enrich", Parallel: parallel, Timeout: "100ms"}}
+			ct, err := newCeHTTPClientTransformer(Config{SenderCreator: senderCreator, Steps: steps, ResponseTemplate: tt.givenCeTemplate, Timeout: time.Second, JSONBody: true, Debug: true})
+			if err != nil {
+				t.Errorf("cehttpclienttransformer error = %v", err)
+				return
+			}
+			incomingEvent := cetransformer.NewEventWithJSONStringData(`{ "sku": "4711" }`)
+			outgoingCe, err := ct.TransformEvent(&incomingEvent)
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("cehttpclienttransformer.TransformEvent error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if err == nil {
+				cetransformer.CompareEvents(t, "cehttpclienttransformer.TransformEvent", *outgoingCe, tt.thenWantOutgoingEvent)
+			}
+		})
+	}
+}
+
+func TestTransformEventParallelCancelsPendingRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenFailing bool
+		givenTimeout string
+	}{
+		{name: "timeout", givenTimeout: "50ms"},
+		{name: "required request fails", givenFailing: true, givenTimeout: "10s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started, cancelled := make(chan struct{}), make(chan struct{})
+			slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-r.Context().Done():
+					close(cancelled)
+				case <-time.After(5 * time.Second):
+				}
+			}))
+			defer slow.Close()
+			parallel := []ParallelRequest{{Name: "slow", RequestTemplate: "GET " + slow.URL + " HTTP/1.1\n\n", Optional: true}}
+			if tt.givenFailing {
+				failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-started
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+				}))
+				defer failing.Close()
+				parallel = append(parallel, ParallelRequest{Name: "failing", RequestTemplate: "GET " + failing.URL + " HTTP/1.1\n\n"})
+			}
+			steps := []Step{{Name: "enrich", Parallel: parallel, Timeout: tt.givenTimeout}}
+			ct, err := NewCeHTTPClientTransformerWithConfig(Config{Steps: steps, ResponseTemplate: `{}`, Timeout: 10 * time.Second, JSONBody: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			incomingEvent := cetransformer.NewEventWithJSONStringData(`{}`)
+			ct.TransformEvent(&incomingEvent)
+			select {
+			case <-cancelled:
+			case <-time.After(time.Second):
+				t.Error("pending parallel request not cancelled")
+			}
+		})
+	}
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 
 	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/logging"
 )
 
 // CeHTTPServer bla
@@ -31,25 +31,26 @@ func NewCeHTTPServer(port int, path string, method string, debug bool, producerH
 	chs.srv = &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: mux}
 
 	go func() {
-		if err := chs.srv.ListenAndServe(); err != nil {
-			log.Printf("cehttpservertransformer.listenAndServe: %v", err)
+		if err := chs.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("cehttpservertransformer.listenAndServe: %v", err)
 		}
 	}()
 
 	return chs
 }
 
-// ShutDown bla
-func (chs *CeHTTPServer) ShutDown() error {
-	if err := chs.srv.Shutdown(context.TODO()); err != nil {
+// ShutDown stops accepting requests and waits for the active requests until the context is done
+func (chs *CeHTTPServer) ShutDown(ctx context.Context) error {
+	if err := chs.srv.Shutdown(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (chs *CeHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Printf("received request: %v", r)
-	result := chs.producerHandler.SendCe(*r)
-	log.Printf("SendCe result: %s", result)
+	logging.Debugf("received request: %s %s", r.Method, r.URL.Path)
+	if result := chs.producerHandler.SendCe(*r); result != nil {
+		logging.Warnf("SendCe result: %s", result)
+	}
 	io.WriteString(w, "event successfully sent!\n")
 }
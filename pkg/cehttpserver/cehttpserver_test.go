@@ -1,6 +1,7 @@
 package cehttpserver
 
 import (
+	"context"
 	"net/http"
 	"testing"
 	"time"
@@ -60,7 +61,7 @@ func TestCeHTTPServer_Serve(t *testing.T) {
 			ceClient := &cetransformer.CeClientMock{T: t, WantSend: true, WantSendEvent: tt.givenProducerEvent, ShouldThrowErrorOnSend: tt.givenCeClientSendError}
 			ceProducerHandler := cehandler.NewProducerHandler(ceProducer, ceClient, "sink", 3*time.Second, true)
 			ceHTTPServer := NewCeHTTPServer(tt.givenServerPort, tt.givenServerPath, tt.givenServerMethod, true, ceProducerHandler)
-			defer ceHTTPServer.ShutDown()
+			defer ceHTTPServer.ShutDown(context.Background())
 			time.Sleep(100 * time.Millisecond)
 			response, err := client.Do(&tt.whenHTTPRequest)
 			if err != nil {
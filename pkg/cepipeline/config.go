@@ -0,0 +1,155 @@
+package cepipeline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"gopkg.in/yaml.v2"
+)
+
+// StepType the kind of a pipeline step
+type StepType string
+
+const (
+	// Filter drops the event if the template doesn't resolve to "true"
+	Filter StepType = "filter"
+	// Mapper transforms the event with the template
+	Mapper StepType = "mapper"
+	// HTTP transforms the event to http requests, the response template creates the outgoing event
+	HTTP StepType = "http"
+	// Splitter the template renders a json array, every element is the data of an outgoing event
+	Splitter StepType = "splitter"
+	// Router runs the steps of the first route whose condition is true
+	Router StepType = "router"
+)
+
+// ErrorPolicy what happens with an event if a step fails
+type ErrorPolicy string
+
+const (
+	// OnErrorFail the event fails, the error is returned to the sender
+	OnErrorFail ErrorPolicy = "fail"
+	// OnErrorSkip the step is skipped, the event goes unchanged to the next step
+	OnErrorSkip ErrorPolicy = "skip"
+	// OnErrorDrop the event is dropped without error
+	OnErrorDrop ErrorPolicy = "drop"
+)
+
+// Config an ordered list of steps
+type Config struct {
+	Steps []StepConfig `yaml:"steps"`
+}
+
+// StepConfig configuration of a step, which keys are used depends on the type
+type StepConfig struct {
+	Name string   `yaml:"name"`
+	Type StepType `yaml:"type"`
+	// Template the go template of filter, mapper and splitter
+	Template string `yaml:"template"`
+	// Source and EventType of the events created by mapper, http and splitter steps. Default is the incoming event
+	Source    string `yaml:"source"`
+	EventType string `yaml:"eventType"`
+	// Request the request template of a http step
+	Request string `yaml:"request"`
+	// Requests a chain of requests of a http step instead of Request
+	Requests []cehttpclienttransformer.Step `yaml:"requests"`
+	// Response the response template of a http step
+	Response string        `yaml:"response"`
+	Timeout  time.Duration `yaml:"timeout"`
+	JSONBody *bool         `yaml:"jsonBody"`
+	// Routes of a router step
+	Routes  []RouteConfig `yaml:"routes"`
+	OnError ErrorPolicy   `yaml:"onError"`
+}
+
+// RouteConfig a route of a router step
+type RouteConfig struct {
+	Name string `yaml:"name"`
+	// When the condition template of the route
+	When  string       `yaml:"when"`
+	Steps []StepConfig `yaml:"steps"`
+	// Sink optional, the events of the route are sent to the sink and leave the pipeline
+	Sink string `yaml:"sink"`
+}
+
+// ParseConfig parses the yaml configuration and sets the defaults
+func ParseConfig(content []byte) (Config, error) {
+	config := Config{}
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return config, fmt.Errorf("can't parse pipeline: %v", err)
+	}
+	if len(config.Steps) == 0 {
+		return config, fmt.Errorf("pipeline has no steps")
+	}
+	if err := setDefaults(config.Steps, ""); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// ReadConfig reads the yaml configuration from a file
+func ReadConfig(fileName string) (Config, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(content)
+}
+
+func setDefaults(steps []StepConfig, prefix string) error {
+	for i := range steps {
+		step := &steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("%d-%s", i+1, step.Type)
+		}
+		step.Name = prefix + step.Name
+		switch step.OnError {
+		case "":
+			step.OnError = OnErrorFail
+		case OnErrorFail, OnErrorSkip, OnErrorDrop:
+		default:
+			return fmt.Errorf("step '%s': unknown error policy '%s'", step.Name, step.OnError)
+		}
+		if step.Timeout == 0 {
+			step.Timeout = time.Second
+		}
+		if step.JSONBody == nil {
+			jsonBody := true
+			step.JSONBody = &jsonBody
+		}
+		switch step.Type {
+		case Filter, Mapper, Splitter:
+			if step.Template == "" {
+				return fmt.Errorf("step '%s': template is missing", step.Name)
+			}
+		case HTTP:
+			if step.Request == "" && len(step.Requests) == 0 {
+				return fmt.Errorf("step '%s': request or requests is missing", step.Name)
+			}
+			if step.Response == "" {
+				step.Response = "{{ .httpresponse.body | toJson }}"
+			}
+		case Router:
+			if len(step.Routes) == 0 {
+				return fmt.Errorf("step '%s': routes are missing", step.Name)
+			}
+			for r := range step.Routes {
+				route := &step.Routes[r]
+				if route.Name == "" {
+					route.Name = fmt.Sprintf("route-%d", r+1)
+				}
+				if route.When == "" {
+					return fmt.Errorf("step '%s': route '%s' has no condition", step.Name, route.Name)
+				}
+				if err := setDefaults(route.Steps, step.Name+"/"+route.Name+"/"); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("step '%s': unknown type '%s'", step.Name, step.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cepipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenContent string
+		thenNames    []string
+		thenError    string
+	}{
+		{name: "defaults", givenContent: `
+steps:
+  - type: filter
+    template: "true"
+  - name: enrich
+    type: http
+    request: "GET http://localhost/ HTTP/1.1\n\n"
+  - type: router
+    routes:
+      - when: "true"
+        steps:
+          - type: mapper
+            template: "{}"
+`, thenNames: []string{"1-filter", "enrich", "3-router"}},
+		{name: "no steps", givenContent: "steps: []", thenError: "pipeline has no steps"},
+		{name: "unknown key", givenContent: "steps:\n  - type: filter\n    templat: x\n", thenError: "can't parse pipeline: yaml: unmarshal errors:\n  line 3: field templat not found in type cepipeline.StepConfig"},
+		{name: "unknown type", givenContent: "steps:\n  - type: foo\n", thenError: "step '1-foo': unknown type 'foo'"},
+		{name: "unknown error policy", givenContent: "steps:\n  - type: filter\n    template: x\n    onError: retry\n", thenError: "step '1-filter': unknown error policy 'retry'"},
+		{name: "missing template", givenContent: "steps:\n  - type: mapper\n", thenError: "step '1-mapper': template is missing"},
+		{name: "missing request", givenContent: "steps:\n  - type: http\n", thenError: "step '1-http': request or requests is missing"},
+		{name: "route without condition", givenContent: "steps:\n  - type: router\n    routes:\n      - steps: []\n", thenError: "step '1-router': route 'route-1' has no condition"},
+		{name: "invalid route step", givenContent: "steps:\n  - type: router\n    routes:\n      - when: 'true'\n        steps:\n          - type: splitter\n", thenError: "step '1-router/route-1/1-splitter': template is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.givenContent))
+			if tt.thenError != "" {
+				if err == nil || err.Error() != tt.thenError {
+					t.Errorf("ParseConfig() error = %v, want %s", err, tt.thenError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+			for i, name := range tt.thenNames {
+				if config.Steps[i].Name != name {
+					t.Errorf("ParseConfig() step %d name = %s, want %s", i, config.Steps[i].Name, name)
+				}
+				if config.Steps[i].OnError != OnErrorFail {
+					t.Errorf("ParseConfig() step %d onError = %s, want %s", i, config.Steps[i].OnError, OnErrorFail)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConfig_httpDefaults(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+steps:
+  - type: http
+    timeout: 500ms
+    requests:
+      - name: lookup
+        request: "GET http://localhost/ HTTP/1.1\n\n"
+        condition: "true"
+  - type: http
+    request: "GET http://localhost/ HTTP/1.1\n\n"
+    jsonBody: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := config.Steps[0], config.Steps[1]
+	if first.Timeout != 500*time.Millisecond || !*first.JSONBody || first.Response != "{{ .httpresponse.body | toJson }}" {
+		t.Errorf("ParseConfig() first step = %+v", first)
+	}
+	if first.Requests[0].Name != "lookup" || first.Requests[0].Condition != "true" {
+		t.Errorf("ParseConfig() requests = %+v", first.Requests)
+	}
+	if second.Timeout != time.Second || *second.JSONBody {
+		t.Errorf("ParseConfig() second step = %+v", second)
+	}
+}
@@ -0,0 +1,65 @@
+package cepipeline
+
+import (
+	"context"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// CePipelineHandler provides callback functions for running cloudEvents through a pipeline
+type CePipelineHandler struct {
+	pipeline *Pipeline
+	ceClient cloudevents.Client
+	sink     string
+	debug    bool
+}
+
+// NewCePipelineHandler start handling cloudEvents until the context is done
+func NewCePipelineHandler(ctx context.Context, pipeline *Pipeline, ceClient cloudevents.Client, sink string, debug bool) (*CePipelineHandler, error) {
+	cph := &CePipelineHandler{pipeline: pipeline, ceClient: ceClient, sink: sink, debug: debug}
+	var receiver interface{} // the SDK reflects on the signature.
+	if len(sink) == 0 {
+		receiver = cph.ReceiveReplyCe
+	} else {
+		receiver = cph.ReceiveSendCe
+	}
+	if err := cph.ceClient.StartReceiver(ctx, receiver); err != nil {
+		return nil, err
+	}
+	return cph, nil
+}
+
+// ReceiveSendCe run the pipeline and send the resulting events to the sink
+func (cph *CePipelineHandler) ReceiveSendCe(ctx context.Context, sourceEvent cloudevents.Event) protocol.Result {
+	events, err := cph.pipeline.Process(ctx, sourceEvent)
+	if err != nil {
+		return http.NewResult(400, "got error %v while processing event %s", err, logging.EventRef(sourceEvent))
+	}
+	for _, event := range events {
+		if cph.debug {
+			logging.DebugEvent("sending event", event)
+		}
+		if result := cph.ceClient.Send(cloudevents.ContextWithTarget(ctx, cph.sink), event); !cloudevents.IsACK(result) {
+			return result
+		}
+	}
+	return nil
+}
+
+// ReceiveReplyCe run the pipeline and put the resulting event in the response
+func (cph *CePipelineHandler) ReceiveReplyCe(ctx context.Context, sourceEvent cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	events, err := cph.pipeline.Process(ctx, sourceEvent)
+	if err != nil {
+		return nil, http.NewResult(400, "got error %v while processing event %s", err, logging.EventRef(sourceEvent))
+	}
+	switch len(events) {
+	case 0:
+		return nil, http.NewResult(204, "event dropped by pipeline")
+	case 1:
+		return &events[0], nil
+	}
+	return nil, http.NewResult(500, "pipeline created %d events, only one can be replied, use a sink", len(events))
+}
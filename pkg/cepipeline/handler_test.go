@@ -0,0 +1,109 @@
+package cepipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+type ceClientRecorder struct {
+	sent      []cloudevents.Event
+	sendError error
+}
+
+func (cr *ceClientRecorder) StartReceiver(ctx context.Context, fn interface{}) error {
+	return nil
+}
+
+func (cr *ceClientRecorder) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	cr.sent = append(cr.sent, event)
+	return cr.sendError
+}
+
+func (cr *ceClientRecorder) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	return nil, nil
+}
+
+const splitPipeline = `
+steps:
+  - type: filter
+    template: '{{ hasKey .data "items" }}'
+  - type: splitter
+    template: '{{ toJson .data.items }}'
+`
+
+func newTestHandler(t *testing.T, client *ceClientRecorder, sink string) *CePipelineHandler {
+	config, err := ParseConfig([]byte(splitPipeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := NewPipeline(config, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewCePipelineHandler(context.Background(), pipeline, client, sink, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return handler
+}
+
+func TestCePipelineHandler_ReceiveSendCe(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenSendError error
+		whenData       string
+		thenSent       int
+		thenWantResult protocol.Result
+	}{
+		{name: "split", whenData: `{"items": [{"id": 1}, {"id": 2}]}`, thenSent: 2},
+		{name: "dropped", whenData: `{"id": 1}`, thenSent: 0},
+		{name: "pipeline error", whenData: `{"items": 1}`,
+			thenWantResult: http.NewResult(400, "got error %v while processing event id='id' type='type' source='source'", errors.New("step '2-splitter' failed: template must render a json array: json: cannot unmarshal number into Go value of type []interface {}"))},
+		{name: "send error", givenSendError: errors.New("test"), whenData: `{"items": [{"id": 1}, {"id": 2}]}`, thenSent: 1, thenWantResult: errors.New("test")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &ceClientRecorder{sendError: tt.givenSendError}
+			result := newTestHandler(t, client, "sink").ReceiveSendCe(context.Background(), cetransformer.NewEventWithJSONStringData(tt.whenData))
+			if !cetransformer.CompareErrors(t, "CePipelineHandler.ReceiveSendCe", result, tt.thenWantResult) {
+				return
+			}
+			if len(client.sent) != tt.thenSent {
+				t.Errorf("CePipelineHandler.ReceiveSendCe sent %d events, want %d", len(client.sent), tt.thenSent)
+			}
+		})
+	}
+}
+
+func TestCePipelineHandler_ReceiveReplyCe(t *testing.T) {
+	tests := []struct {
+		name           string
+		whenData       string
+		thenWantEvent  string
+		thenWantResult protocol.Result
+	}{
+		{name: "reply", whenData: `{"items": [{"id": "1"}]}`, thenWantEvent: `{"id": "1"}`},
+		{name: "dropped", whenData: `{"id": 1}`, thenWantResult: http.NewResult(204, "event dropped by pipeline")},
+		{name: "too many events", whenData: `{"items": [{"id": 1}, {"id": 2}]}`,
+			thenWantResult: http.NewResult(500, "pipeline created %d events, only one can be replied, use a sink", 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, result := newTestHandler(t, &ceClientRecorder{}, "").ReceiveReplyCe(context.Background(), cetransformer.NewEventWithJSONStringData(tt.whenData))
+			if !cetransformer.CompareErrors(t, "CePipelineHandler.ReceiveReplyCe", result, tt.thenWantResult) {
+				return
+			}
+			if tt.thenWantEvent != "" {
+				want := cetransformer.NewEventWithJSONStringData(tt.thenWantEvent)
+				want.SetID("id-0")
+				cetransformer.CompareEvents(t, "CePipelineHandler.ReceiveReplyCe", *event, want)
+			}
+		})
+	}
+}
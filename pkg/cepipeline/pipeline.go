@@ -0,0 +1,304 @@
+package cepipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/logging"
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// SendFunc sends an event to a sink
+type SendFunc func(ctx context.Context, sink string, event cloudevents.Event) error
+
+// Pipeline executes the steps in order. Every step gets the events of the previous step
+type Pipeline struct {
+	steps   []*step
+	metrics []*stepMetrics
+	debug   bool
+}
+
+// StepMetrics counters of a step
+type StepMetrics struct {
+	Name string
+	// In events processed by the step
+	In uint64
+	// Out events created by the step
+	Out uint64
+	// Dropped events dropped by a filter, a route with sink or the error policy drop
+	Dropped uint64
+	// Errors failed executions of the step
+	Errors uint64
+	// Duration total processing time of the step
+	Duration time.Duration
+}
+
+func (sm StepMetrics) String() string {
+	return fmt.Sprintf("step '%s': in=%d out=%d dropped=%d errors=%d duration=%v", sm.Name, sm.In, sm.Out, sm.Dropped, sm.Errors, sm.Duration)
+}
+
+type stepMetrics struct {
+	name     string
+	in       uint64
+	out      uint64
+	dropped  uint64
+	errors   uint64
+	duration int64
+}
+
+type processor interface {
+	process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error)
+}
+
+type step struct {
+	name      string
+	onError   ErrorPolicy
+	processor processor
+	metrics   *stepMetrics
+}
+
+// NewPipeline parses all templates of the steps. send is only needed for routes with a sink
+func NewPipeline(config Config, send SendFunc, debug bool) (*Pipeline, error) {
+	p := &Pipeline{debug: debug}
+	steps, err := p.newSteps(config.Steps, send)
+	if err != nil {
+		return nil, err
+	}
+	p.steps = steps
+	return p, nil
+}
+
+func (p *Pipeline) newSteps(configs []StepConfig, send SendFunc) ([]*step, error) {
+	steps := []*step{}
+	for _, config := range configs {
+		metrics := &stepMetrics{name: config.Name}
+		p.metrics = append(p.metrics, metrics)
+		processor, err := p.newProcessor(config, send)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %v", config.Name, err)
+		}
+		steps = append(steps, &step{name: config.Name, onError: config.OnError, processor: processor, metrics: metrics})
+	}
+	return steps, nil
+}
+
+func (p *Pipeline) newProcessor(config StepConfig, send SendFunc) (processor, error) {
+	switch config.Type {
+	case Filter:
+		predicate, err := cetransformer.NewCloudEventTransformer(config.Template, "", "", p.debug)
+		if err != nil {
+			return nil, err
+		}
+		return filterProcessor{predicate: predicate}, nil
+	case Mapper:
+		mapper, err := cetransformer.NewCloudEventTransformer(config.Template, config.Source, config.EventType, p.debug)
+		if err != nil {
+			return nil, err
+		}
+		return mapperProcessor{mapper: mapper}, nil
+	case HTTP:
+		mapper, err := newHTTPMapper(config, p.debug)
+		if err != nil {
+			return nil, err
+		}
+		return mapperProcessor{mapper: mapper, source: config.Source, eventType: config.EventType}, nil
+	case Splitter:
+		tplt, err := transformer.NewTransformer(config.Template, nil, p.debug)
+		if err != nil {
+			return nil, err
+		}
+		return splitterProcessor{transformer: tplt, source: config.Source, eventType: config.EventType}, nil
+	case Router:
+		router := routerProcessor{send: send}
+		for _, routeConfig := range config.Routes {
+			when, err := cetransformer.NewCloudEventTransformer(routeConfig.When, "", "", p.debug)
+			if err != nil {
+				return nil, fmt.Errorf("route '%s': %v", routeConfig.Name, err)
+			}
+			if routeConfig.Sink != "" && send == nil {
+				return nil, fmt.Errorf("route '%s': can't send to sink '%s'", routeConfig.Name, routeConfig.Sink)
+			}
+			steps, err := p.newSteps(routeConfig.Steps, send)
+			if err != nil {
+				return nil, err
+			}
+			router.routes = append(router.routes, route{name: routeConfig.Name, when: when, steps: steps, sink: routeConfig.Sink})
+		}
+		return router, nil
+	}
+	return nil, fmt.Errorf("unknown type '%s'", config.Type)
+}
+
+func newHTTPMapper(config StepConfig, debug bool) (cehandler.CeMapper, error) {
+	steps := config.Requests
+	if len(steps) == 0 {
+		steps = []cehttpclienttransformer.Step{{Name: "request", RequestTemplate: config.Request}}
+	}
+	return cehttpclienttransformer.NewCeHTTPClientTransformerWithConfig(cehttpclienttransformer.Config{Steps: steps, ResponseTemplate: config.Response,
+		Timeout: config.Timeout, JSONBody: *config.JSONBody, OnlyPayload: true, Debug: debug})
+}
+
+// Process runs the event through all steps and returns the resulting events
+func (p *Pipeline) Process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	return runSteps(ctx, p.steps, []cloudevents.Event{event})
+}
+
+func runSteps(ctx context.Context, steps []*step, events []cloudevents.Event) ([]cloudevents.Event, error) {
+	for _, s := range steps {
+		next := []cloudevents.Event{}
+		for _, event := range events {
+			result, err := s.run(ctx, event)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, result...)
+		}
+		events = next
+		if len(events) == 0 {
+			break
+		}
+	}
+	return events, nil
+}
+
+func (s *step) run(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	atomic.AddUint64(&s.metrics.in, 1)
+	start := time.Now()
+	result, err := s.processor.process(ctx, event)
+	atomic.AddInt64(&s.metrics.duration, int64(time.Since(start)))
+	if err != nil {
+		atomic.AddUint64(&s.metrics.errors, 1)
+		switch s.onError {
+		case OnErrorSkip:
+			logging.ForEvent(event).Warnf("step '%s' skipped, error: %v", s.name, err)
+			result = []cloudevents.Event{event}
+		case OnErrorDrop:
+			logging.ForEvent(event).Warnf("step '%s' dropped event, error: %v", s.name, err)
+			result = nil
+		default:
+			return nil, fmt.Errorf("step '%s' failed: %v", s.name, err)
+		}
+	}
+	if len(result) == 0 {
+		atomic.AddUint64(&s.metrics.dropped, 1)
+	}
+	atomic.AddUint64(&s.metrics.out, uint64(len(result)))
+	return result, nil
+}
+
+// Metrics the counters of all steps including the steps of routes
+func (p *Pipeline) Metrics() []StepMetrics {
+	metrics := []StepMetrics{}
+	for _, m := range p.metrics {
+		metrics = append(metrics, StepMetrics{Name: m.name, In: atomic.LoadUint64(&m.in), Out: atomic.LoadUint64(&m.out),
+			Dropped: atomic.LoadUint64(&m.dropped), Errors: atomic.LoadUint64(&m.errors), Duration: time.Duration(atomic.LoadInt64(&m.duration))})
+	}
+	return metrics
+}
+
+type filterProcessor struct {
+	predicate cehandler.CeFilter
+}
+
+func (fp filterProcessor) process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	ok, err := fp.predicate.PredicateEvent(&event)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []cloudevents.Event{event}, nil
+}
+
+type mapperProcessor struct {
+	mapper    cehandler.CeMapper
+	source    string
+	eventType string
+}
+
+func (mp mapperProcessor) process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	result, err := mp.mapper.TransformEvent(&event)
+	if err != nil {
+		return nil, err
+	}
+	setSourceAndType(result, mp.source, mp.eventType)
+	return []cloudevents.Event{*result}, nil
+}
+
+type splitterProcessor struct {
+	transformer *transformer.Transformer
+	source      string
+	eventType   string
+}
+
+func (sp splitterProcessor) process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	content, err := sp.transformer.TransformInputToBytes(cetransformer.EventToMap(&event))
+	if err != nil {
+		return nil, err
+	}
+	items := []interface{}{}
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, fmt.Errorf("template must render a json array: %v", err)
+	}
+	events := []cloudevents.Event{}
+	for i, item := range items {
+		splitEvent := event.Clone()
+		splitEvent.SetID(fmt.Sprintf("%s-%d", event.ID(), i))
+		if err := splitEvent.SetData(cloudevents.ApplicationJSON, item); err != nil {
+			return nil, err
+		}
+		setSourceAndType(&splitEvent, sp.source, sp.eventType)
+		events = append(events, splitEvent)
+	}
+	return events, nil
+}
+
+type route struct {
+	name  string
+	when  cehandler.CeFilter
+	steps []*step
+	sink  string
+}
+
+type routerProcessor struct {
+	routes []route
+	send   SendFunc
+}
+
+// process the first matching route gets the event, without matching route the event is unchanged
+func (rp routerProcessor) process(ctx context.Context, event cloudevents.Event) ([]cloudevents.Event, error) {
+	for _, r := range rp.routes {
+		ok, err := r.when.PredicateEvent(&event)
+		if err != nil {
+			return nil, fmt.Errorf("route '%s': %v", r.name, err)
+		}
+		if !ok {
+			continue
+		}
+		events, err := runSteps(ctx, r.steps, []cloudevents.Event{event})
+		if err != nil || r.sink == "" {
+			return events, err
+		}
+		for _, e := range events {
+			if err := rp.send(ctx, r.sink, e); err != nil {
+				return nil, fmt.Errorf("route '%s': failed to send event to '%s': %v", r.name, r.sink, err)
+			}
+		}
+		return nil, nil
+	}
+	return []cloudevents.Event{event}, nil
+}
+
+func setSourceAndType(event *cloudevents.Event, source, eventType string) {
+	if source != "" {
+		event.SetSource(source)
+	}
+	if eventType != "" {
+		event.SetType(eventType)
+	}
+}
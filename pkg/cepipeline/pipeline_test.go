@@ -0,0 +1,226 @@
+package cepipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+type sentEvent struct {
+	sink  string
+	event cloudevents.Event
+}
+
+func TestPipeline_Process(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"gender": "female", "path": "%s"}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		givenPipeline string
+		givenSendErr  error
+		whenData      string
+		thenData      []string
+		thenSent      []string
+		thenError     string
+	}{
+		{name: "filter passes", givenPipeline: `
+steps:
+  - type: filter
+    template: '{{ eq .data.name "Alex" }}'
+`, whenData: `{"name": "Alex"}`, thenData: []string{`{"name": "Alex"}`}},
+		{name: "filter drops", givenPipeline: `
+steps:
+  - type: filter
+    template: '{{ eq .data.name "Alex" }}'
+  - type: mapper
+    template: '{}'
+`, whenData: `{"name": "Bob"}`, thenData: []string{}},
+		{name: "filter mapper http", givenPipeline: `
+steps:
+  - type: filter
+    template: '{{ eq .data.name "Alex" }}'
+  - type: mapper
+    template: '{"person": "{{ .data.name }}"}'
+  - type: http
+    request: "GET ` + server.URL + `/{{ .data.person }} HTTP/1.1\n\n"
+    response: '{"name": "{{ .inputce.data.person }}", "gender": "{{ .httpresponse.body.gender }}", "path": "{{ .httpresponse.body.path }}"}'
+`, whenData: `{"name": "Alex"}`, thenData: []string{`{"name": "Alex", "gender": "female", "path": "/Alex"}`}},
+		{name: "splitter", givenPipeline: `
+steps:
+  - type: splitter
+    template: '{{ toJson .data.items }}'
+  - type: mapper
+    template: '{"item": "{{ .data.id }}"}'
+`, whenData: `{"items": [{"id": "a"}, {"id": "b"}]}`, thenData: []string{`{"item": "a"}`, `{"item": "b"}`}},
+		{name: "splitter no array", givenPipeline: `
+steps:
+  - type: splitter
+    template: '{{ toJson .data }}'
+`, whenData: `{"items": []}`, thenError: "step '1-splitter' failed: template must render a json array: json: cannot unmarshal object into Go value of type []interface {}"},
+		{name: "router first matching route", givenPipeline: `
+steps:
+  - type: router
+    routes:
+      - when: '{{ eq .data.kind "a" }}'
+        steps:
+          - type: mapper
+            template: '{"route": "a"}'
+      - when: 'true'
+        steps:
+          - type: mapper
+            template: '{"route": "default"}'
+  - type: mapper
+    template: '{"result": "{{ .data.route }}"}'
+`, whenData: `{"kind": "a"}`, thenData: []string{`{"result": "a"}`}},
+		{name: "router without matching route", givenPipeline: `
+steps:
+  - type: router
+    routes:
+      - when: '{{ eq .data.kind "a" }}'
+        steps: []
+`, whenData: `{"kind": "b"}`, thenData: []string{`{"kind": "b"}`}},
+		{name: "router with sink", givenPipeline: `
+steps:
+  - type: router
+    routes:
+      - when: 'true'
+        sink: http://archive
+        steps:
+          - type: mapper
+            template: '{"archived": true}'
+`, whenData: `{"kind": "b"}`, thenData: []string{}, thenSent: []string{"http://archive"}},
+		{name: "router with sink error", givenPipeline: `
+steps:
+  - name: archive
+    type: router
+    routes:
+      - name: all
+        when: 'true'
+        sink: http://archive
+`, givenSendErr: errors.New("unreachable"), whenData: `{"kind": "b"}`, thenError: "step 'archive' failed: route 'all': failed to send event to 'http://archive': unreachable"},
+		{name: "error fails", givenPipeline: `
+steps:
+  - name: enrich
+    type: http
+    request: "GET ` + server.URL + `/fail HTTP/1.1\n\n"
+`, whenData: `{"name": "Alex"}`, thenError: "step 'enrich' failed: "},
+		{name: "error skips", givenPipeline: `
+steps:
+  - type: http
+    request: "GET ` + server.URL + `/fail HTTP/1.1\n\n"
+    response: '{{ .httpresponse.body.notexisting.field }}'
+    onError: skip
+`, whenData: `{"name": "Alex"}`, thenData: []string{`{"name": "Alex"}`}},
+		{name: "error drops", givenPipeline: `
+steps:
+  - type: mapper
+    template: '{{ .data.name.first }}'
+    onError: drop
+`, whenData: `{"name": "Alex"}`, thenData: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.givenPipeline))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sent := []sentEvent{}
+			pipeline, err := NewPipeline(config, func(ctx context.Context, sink string, event cloudevents.Event) error {
+				sent = append(sent, sentEvent{sink: sink, event: event})
+				return tt.givenSendErr
+			}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			events, err := pipeline.Process(context.Background(), cetransformer.NewEventWithJSONStringData(tt.whenData))
+			if tt.thenError != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.thenError) {
+					t.Errorf("Pipeline.Process() error = %v, want %s", err, tt.thenError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Pipeline.Process() error = %v", err)
+			}
+			if len(events) != len(tt.thenData) {
+				t.Fatalf("Pipeline.Process() events = %v, want %d events", events, len(tt.thenData))
+			}
+			for i, data := range tt.thenData {
+				cetransformer.CompareEvents(t, "Pipeline.Process()", events[i], cetransformer.NewEventWithJSONStringData(data))
+			}
+			if len(sent) != len(tt.thenSent) {
+				t.Fatalf("Pipeline.Process() sent = %v, want %v", sent, tt.thenSent)
+			}
+			for i, sink := range tt.thenSent {
+				if sent[i].sink != sink {
+					t.Errorf("Pipeline.Process() sent to %s, want %s", sent[i].sink, sink)
+				}
+			}
+		})
+	}
+}
+
+func TestPipeline_Metrics(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+steps:
+  - name: vip
+    type: filter
+    template: '{{ eq .data.vip true }}'
+  - name: split
+    type: splitter
+    template: '{{ toJson .data.items }}'
+  - name: item
+    type: mapper
+    template: '{"first": "{{ .data.name.first }}"}'
+    onError: drop
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := NewPipeline(config, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{`{"vip": true, "items": [{"name": {"first": "a"}}, {"name": "b"}]}`, `{"vip": false}`} {
+		if _, err := pipeline.Process(context.Background(), cetransformer.NewEventWithJSONStringData(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []StepMetrics{
+		{Name: "vip", In: 2, Out: 1, Dropped: 1},
+		{Name: "split", In: 1, Out: 2},
+		{Name: "item", In: 2, Out: 1, Dropped: 1, Errors: 1},
+	}
+	metrics := pipeline.Metrics()
+	for i := range want {
+		metrics[i].Duration = 0
+		if metrics[i] != want[i] {
+			t.Errorf("Pipeline.Metrics()[%d] = %v, want %v", i, metrics[i], want[i])
+		}
+	}
+}
+
+func TestNewPipeline_routeSinkWithoutSend(t *testing.T) {
+	config, err := ParseConfig([]byte("steps:\n  - type: router\n    routes:\n      - when: 'true'\n        sink: http://archive\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPipeline(config, nil, false); err == nil {
+		t.Error("NewPipeline() error = nil, want error")
+	}
+}
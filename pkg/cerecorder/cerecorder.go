@@ -0,0 +1,75 @@
+package cerecorder
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// LineWriter writes a line
+type LineWriter interface {
+	WriteLine(line []byte) error
+}
+
+// CeRecorder writes received events as json lines
+type CeRecorder struct {
+	writer LineWriter
+	filter cehandler.CeFilter
+	mapper cehandler.CeMapper
+	tee    bool
+	debug  bool
+}
+
+// NewCeRecorder filter and mapper are optional. In tee mode the received event is the reply.
+func NewCeRecorder(writer LineWriter, filter cehandler.CeFilter, mapper cehandler.CeMapper, tee, debug bool) *CeRecorder {
+	return &CeRecorder{writer: writer, filter: filter, mapper: mapper, tee: tee, debug: debug}
+}
+
+// HandleCe records the event if the filter is true
+func (cr *CeRecorder) HandleCe(ctx context.Context, sourceEvent cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	if err := cr.record(sourceEvent); err != nil {
+		return nil, err
+	}
+	if cr.tee {
+		return &sourceEvent, nil
+	}
+	return nil, http.NewResult(202, "recorded")
+}
+
+func (cr *CeRecorder) record(sourceEvent cloudevents.Event) protocol.Result {
+	if cr.filter != nil {
+		ok, err := cr.filter.PredicateEvent(&sourceEvent)
+		if err != nil {
+			return http.NewResult(400, "got error %v while filtering event %s", err, logging.EventRef(sourceEvent))
+		}
+		if !ok {
+			if cr.debug {
+				logging.ForEvent(sourceEvent).Debugf("event not recorded")
+			}
+			return nil
+		}
+	}
+	event := &sourceEvent
+	if cr.mapper != nil {
+		var err error
+		if event, err = cr.mapper.TransformEvent(&sourceEvent); err != nil {
+			return http.NewResult(400, "got error %v while transforming event %s", err, logging.EventRef(sourceEvent))
+		}
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return http.NewResult(400, "got error %v while marshalling event %s", err, logging.EventRef(*event))
+	}
+	if err := cr.writer.WriteLine(line); err != nil {
+		return http.NewResult(500, "got error %v while writing event %s", err, logging.EventRef(*event))
+	}
+	if cr.debug {
+		logging.ForEvent(*event).DebugPayload("recorded event", line)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package cerecorder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+type lineWriterMock struct {
+	lines []string
+	err   error
+}
+
+func (lw *lineWriterMock) WriteLine(line []byte) error {
+	lw.lines = append(lw.lines, string(line))
+	return lw.err
+}
+
+func TestCeRecorder_HandleCe(t *testing.T) {
+	inputEvent := cetransformer.NewEventWithJSONStringData(`{"name": "Alex"}`)
+	mappedEvent := cetransformer.NewEventWithJSONStringData(`{"name": "ALEX"}`)
+	filter, _ := cetransformer.NewCloudEventTransformer(`{{ eq .data.name "Alex" }}`, "", "", false)
+	rejectingFilter, _ := cetransformer.NewCloudEventTransformer(`false`, "", "", false)
+	mapper, _ := cetransformer.NewCloudEventTransformer(`{"name": {{ .data.name | upper | quote }}}`, "", "", false)
+	tests := []struct {
+		name          string
+		givenFilter   cehandler.CeFilter
+		givenMapper   cehandler.CeMapper
+		givenTee      bool
+		givenWriteErr error
+		thenRecorded  *cloudevents.Event
+		thenReply     *cloudevents.Event
+		thenResult    protocol.Result
+	}{
+		{name: "record",
+			thenRecorded: &inputEvent,
+			thenResult:   http.NewResult(202, "recorded")},
+		{name: "tee",
+			givenTee:     true,
+			thenRecorded: &inputEvent,
+			thenReply:    &inputEvent},
+		{name: "filter true",
+			givenFilter:  filter,
+			thenRecorded: &inputEvent,
+			thenResult:   http.NewResult(202, "recorded")},
+		{name: "filter false in tee mode",
+			givenFilter: rejectingFilter,
+			givenTee:    true,
+			thenReply:   &inputEvent},
+		{name: "mapper",
+			givenMapper:  mapper,
+			givenTee:     true,
+			thenRecorded: &mappedEvent,
+			thenReply:    &inputEvent},
+		{name: "write error",
+			givenWriteErr: errors.New("disk full"),
+			thenRecorded:  &inputEvent,
+			thenResult:    http.NewResult(500, "got error %v while writing event id='id' type='type' source='source'", errors.New("disk full"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &lineWriterMock{err: tt.givenWriteErr}
+			recorder := NewCeRecorder(writer, tt.givenFilter, tt.givenMapper, tt.givenTee, true)
+			reply, result := recorder.HandleCe(context.Background(), inputEvent)
+			if !cetransformer.CompareErrors(t, "CeRecorder.HandleCe", result, tt.thenResult) {
+				return
+			}
+			if (reply == nil) != (tt.thenReply == nil) || (reply != nil && !cetransformer.CompareEvents(t, "CeRecorder.HandleCe reply", *reply, *tt.thenReply)) {
+				t.Errorf("CeRecorder.HandleCe() reply = %v, want %v", reply, tt.thenReply)
+			}
+			if tt.thenRecorded == nil {
+				if len(writer.lines) > 0 {
+					t.Errorf("CeRecorder.HandleCe() recorded %v, want nothing", writer.lines)
+				}
+				return
+			}
+			if len(writer.lines) != 1 {
+				t.Fatalf("CeRecorder.HandleCe() recorded %d lines, want 1", len(writer.lines))
+			}
+			recorded := cloudevents.NewEvent()
+			if err := json.Unmarshal([]byte(writer.lines[0]), &recorded); err != nil {
+				t.Fatal(err)
+			}
+			cetransformer.CompareEvents(t, "CeRecorder.HandleCe recorded", recorded, *tt.thenRecorded)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package cerecorder
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WriterConfig configuration of a rotating writer
+type WriterConfig struct {
+	// Dir directory of the files
+	Dir string
+	// Prefix of the file names, followed by the creation time
+	Prefix string
+	// MaxSize file is rotated when it reaches this size in bytes, no limit if 0
+	MaxSize int64
+	// MaxAge file is rotated when it is older, no limit if 0
+	MaxAge time.Duration
+	// Compress rotated files are compressed with gzip
+	Compress bool
+}
+
+// RotatingWriter appends lines to a file, which is rotated by size or age
+type RotatingWriter struct {
+	config  WriterConfig
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	created time.Time
+	now     func() time.Time
+}
+
+// NewRotatingWriter bla
+func NewRotatingWriter(config WriterConfig) (*RotatingWriter, error) {
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+	return &RotatingWriter{config: config, now: time.Now}, nil
+}
+
+// WriteLine appends a line, the file is opened lazily
+func (rw *RotatingWriter) WriteLine(line []byte) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file != nil && rw.mustRotate(int64(len(line)+1)) {
+		if err := rw.rotate(); err != nil {
+			return err
+		}
+	}
+	if rw.file == nil {
+		if err := rw.open(); err != nil {
+			return err
+		}
+	}
+	n, err := rw.file.Write(append(line, '\n'))
+	rw.size += int64(n)
+	return err
+}
+
+// Close closes and, if configured, compresses the current file
+func (rw *RotatingWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file == nil {
+		return nil
+	}
+	return rw.rotate()
+}
+
+// FileName of the current file, empty if no file is open
+func (rw *RotatingWriter) FileName() string {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.file == nil {
+		return ""
+	}
+	return rw.file.Name()
+}
+
+func (rw *RotatingWriter) mustRotate(lineSize int64) bool {
+	if rw.config.MaxSize > 0 && rw.size > 0 && rw.size+lineSize > rw.config.MaxSize {
+		return true
+	}
+	return rw.config.MaxAge > 0 && rw.now().Sub(rw.created) >= rw.config.MaxAge
+}
+
+func (rw *RotatingWriter) open() error {
+	rw.created = rw.now()
+	name := filepath.Join(rw.config.Dir, fmt.Sprintf("%s-%s.jsonl", rw.config.Prefix, rw.created.UTC().Format("20060102T150405.000000000")))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	rw.file = file
+	rw.size = info.Size()
+	return nil
+}
+
+func (rw *RotatingWriter) rotate() error {
+	name := rw.file.Name()
+	err := rw.file.Close()
+	rw.file = nil
+	rw.size = 0
+	if err != nil {
+		return err
+	}
+	if rw.config.Compress {
+		return compress(name)
+	}
+	return nil
+}
+
+// compress replaces a file by its gzip compressed version
+func compress(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
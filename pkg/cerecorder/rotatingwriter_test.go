@@ -0,0 +1,128 @@
+package cerecorder
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readDir(t *testing.T, dir string) map[string]string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{}
+	for _, f := range files {
+		file, err := os.Open(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var content []byte
+		if strings.HasSuffix(f.Name(), ".gz") {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err = ioutil.ReadAll(gz)
+		} else {
+			content, err = ioutil.ReadAll(file)
+		}
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[f.Name()] = string(content)
+	}
+	return contents
+}
+
+func sortedValues(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := []string{}
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+func TestRotatingWriter_WriteLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenConfig  WriterConfig
+		givenStep    time.Duration
+		whenLines    []string
+		thenContents []string
+		thenSuffix   string
+	}{
+		{name: "no rotation",
+			givenConfig:  WriterConfig{Prefix: "events"},
+			givenStep:    time.Second,
+			whenLines:    []string{"a", "b", "c"},
+			thenContents: []string{"a\nb\nc\n"},
+			thenSuffix:   ".jsonl"},
+		{name: "rotation by size",
+			givenConfig:  WriterConfig{Prefix: "events", MaxSize: 4},
+			givenStep:    time.Second,
+			whenLines:    []string{"a", "b", "c"},
+			thenContents: []string{"a\nb\n", "c\n"},
+			thenSuffix:   ".jsonl"},
+		{name: "rotation by age",
+			givenConfig:  WriterConfig{Prefix: "events", MaxAge: 2 * time.Second},
+			givenStep:    time.Second,
+			whenLines:    []string{"a", "b", "c", "d"},
+			thenContents: []string{"a\nb\n", "c\nd\n"},
+			thenSuffix:   ".jsonl"},
+		{name: "compressed",
+			givenConfig:  WriterConfig{Prefix: "events", MaxSize: 4, Compress: true},
+			givenStep:    time.Second,
+			whenLines:    []string{"a", "b", "c"},
+			thenContents: []string{"a\nb\n", "c\n"},
+			thenSuffix:   ".jsonl.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "recorder")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			tt.givenConfig.Dir = dir
+			rw, err := NewRotatingWriter(tt.givenConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)
+			rw.now = func() time.Time {
+				now = now.Add(tt.givenStep)
+				return now
+			}
+			for _, line := range tt.whenLines {
+				if err := rw.WriteLine([]byte(line)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := rw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			contents := readDir(t, dir)
+			for name := range contents {
+				if !strings.HasPrefix(name, "events-") || !strings.HasSuffix(name, tt.thenSuffix) {
+					t.Errorf("unexpected file name '%s'", name)
+				}
+			}
+			got := sortedValues(contents)
+			if strings.Join(got, "|") != strings.Join(tt.thenContents, "|") {
+				t.Errorf("RotatingWriter.WriteLine() files = %q, want %q", got, tt.thenContents)
+			}
+		})
+	}
+}
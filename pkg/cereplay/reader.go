@@ -0,0 +1,99 @@
+package cereplay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// ReadEvents reads structured mode cloud events from a file or all files of a directory in lexical order.
+// Files with extension .jsonl or .ndjson contain one event per line, other files a single event or a json array of events.
+func ReadEvents(path string) ([]cloudevents.Event, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return readFile(path)
+	}
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, f := range files {
+		if !f.IsDir() && isEventFile(f.Name()) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	events := []cloudevents.Event{}
+	for _, name := range names {
+		fileEvents, err := readFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, fileEvents...)
+	}
+	return events, nil
+}
+
+func isEventFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+func readFile(fileName string) ([]cloudevents.Event, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".jsonl", ".ndjson":
+		return readLines(fileName, content)
+	}
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		events := []cloudevents.Event{}
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, fmt.Errorf("%s: %v", fileName, err)
+		}
+		return events, nil
+	}
+	event := cloudevents.NewEvent()
+	if err := json.Unmarshal(trimmed, &event); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return []cloudevents.Event{event}, nil
+}
+
+func readLines(fileName string, content []byte) ([]cloudevents.Event, error) {
+	events := []cloudevents.Event{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		event := cloudevents.NewEvent()
+		if err := json.Unmarshal(text, &event); err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", fileName, line, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
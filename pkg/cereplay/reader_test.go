@@ -0,0 +1,75 @@
+package cereplay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	event1 = `{"specversion":"1.0","id":"1","source":"test","type":"order","time":"2020-11-01T10:00:00Z","datacontenttype":"application/json","data":{"order":1}}`
+	event2 = `{"specversion":"1.0","id":"2","source":"test","type":"order","time":"2020-11-01T10:00:02Z","datacontenttype":"application/json","data":{"order":2}}`
+)
+
+func TestReadEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name       string
+		givenFiles map[string]string
+		whenPath   string
+		thenIDs    []string
+		thenError  bool
+	}{
+		{name: "jsonl",
+			givenFiles: map[string]string{"events.jsonl": event1 + "\n\n" + event2 + "\n"},
+			whenPath:   "events.jsonl",
+			thenIDs:    []string{"1", "2"}},
+		{name: "json single event",
+			givenFiles: map[string]string{"event.json": event2},
+			whenPath:   "event.json",
+			thenIDs:    []string{"2"}},
+		{name: "json array",
+			givenFiles: map[string]string{"array.json": "[" + event2 + "," + event1 + "]"},
+			whenPath:   "array.json",
+			thenIDs:    []string{"2", "1"}},
+		{name: "directory",
+			givenFiles: map[string]string{"dir/b.json": event2, "dir/a.json": event1, "dir/readme.txt": "no event"},
+			whenPath:   "dir",
+			thenIDs:    []string{"1", "2"}},
+		{name: "invalid line",
+			givenFiles: map[string]string{"invalid.jsonl": event1 + "\n{"},
+			whenPath:   "invalid.jsonl",
+			thenError:  true},
+		{name: "missing file",
+			whenPath:  "missing.json",
+			thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, content := range tt.givenFiles {
+				fileName := filepath.Join(dir, name)
+				os.MkdirAll(filepath.Dir(fileName), 0755)
+				if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			events, err := ReadEvents(filepath.Join(dir, tt.whenPath))
+			if (err != nil) != tt.thenError {
+				t.Fatalf("ReadEvents() error = %v, thenError %v", err, tt.thenError)
+			}
+			if len(events) != len(tt.thenIDs) {
+				t.Fatalf("ReadEvents() got %d events, want %d", len(events), len(tt.thenIDs))
+			}
+			for i, event := range events {
+				if event.ID() != tt.thenIDs[i] {
+					t.Errorf("ReadEvents() event %d has id %s, want %s", i, event.ID(), tt.thenIDs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package cereplay
+
+import (
+	"context"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Config configuration of a replayer
+type Config struct {
+	// OriginalTiming the delay between two events is the difference of their time attributes
+	OriginalTiming bool
+	// Speed multiplier of the original timing, e.g. 2 replays twice as fast
+	Speed float64
+	// Loop replay the events again after the last one until the context is done, with original timing the first event
+	// of the next loop is delayed by the gap between the first two events
+	Loop bool
+}
+
+// Replayer sends recorded events again
+type Replayer struct {
+	config Config
+	after  func(time.Duration) <-chan time.Time
+}
+
+// NewReplayer bla
+func NewReplayer(config Config) *Replayer {
+	if config.Speed <= 0 {
+		config.Speed = 1
+	}
+	return &Replayer{config: config, after: time.After}
+}
+
+// Run calls send for every event until all events are replayed or the context is done. An error of send stops the replay.
+func (r *Replayer) Run(ctx context.Context, events []cloudevents.Event, send func(cloudevents.Event) error) error {
+	for loop := 0; ; loop++ {
+		for i, event := range events {
+			var delay time.Duration
+			switch {
+			case i > 0:
+				delay = r.delay(events[i-1], event)
+			case loop > 0 && len(events) > 1:
+				delay = r.delay(events[0], events[1])
+			}
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-r.after(delay):
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		if !r.config.Loop || len(events) == 0 {
+			return nil
+		}
+	}
+}
+
+// delay between two events, 0 if one of the events has no time attribute or the events are out of order
+func (r *Replayer) delay(previous, next cloudevents.Event) time.Duration {
+	if !r.config.OriginalTiming || previous.Time().IsZero() || next.Time().IsZero() {
+		return 0
+	}
+	return time.Duration(float64(next.Time().Sub(previous.Time())) / r.config.Speed)
+}
@@ -0,0 +1,95 @@
+package cereplay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func testEvents(t *testing.T, jsonEvents ...string) []cloudevents.Event {
+	events := []cloudevents.Event{}
+	for _, jsonEvent := range jsonEvents {
+		event := cloudevents.NewEvent()
+		if err := json.Unmarshal([]byte(jsonEvent), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestReplayer_Run(t *testing.T) {
+	tests := []struct {
+		name        string
+		givenConfig Config
+		givenSent   int
+		thenIDs     []string
+		thenDelays  []time.Duration
+	}{
+		{name: "no timing",
+			givenConfig: Config{},
+			thenIDs:     []string{"1", "2"},
+			thenDelays:  []time.Duration{}},
+		{name: "original timing",
+			givenConfig: Config{OriginalTiming: true},
+			thenIDs:     []string{"1", "2"},
+			thenDelays:  []time.Duration{2 * time.Second}},
+		{name: "speed",
+			givenConfig: Config{OriginalTiming: true, Speed: 4},
+			thenIDs:     []string{"1", "2"},
+			thenDelays:  []time.Duration{500 * time.Millisecond}},
+		{name: "loop",
+			givenConfig: Config{Loop: true},
+			givenSent:   5,
+			thenIDs:     []string{"1", "2", "1", "2", "1"},
+			thenDelays:  []time.Duration{}},
+		{name: "loop with original timing",
+			givenConfig: Config{OriginalTiming: true, Loop: true},
+			givenSent:   4,
+			thenIDs:     []string{"1", "2", "1", "2"},
+			thenDelays:  []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReplayer(tt.givenConfig)
+			delays := []time.Duration{}
+			r.after = func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				c := make(chan time.Time, 1)
+				c <- time.Now()
+				return c
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ids := []string{}
+			r.Run(ctx, testEvents(t, event1, event2), func(event cloudevents.Event) error {
+				ids = append(ids, event.ID())
+				if len(ids) == tt.givenSent {
+					cancel()
+				}
+				return nil
+			})
+			if !reflect.DeepEqual(ids, tt.thenIDs) {
+				t.Errorf("Replayer.Run() sent %v, want %v", ids, tt.thenIDs)
+			}
+			if !reflect.DeepEqual(delays, tt.thenDelays) {
+				t.Errorf("Replayer.Run() delays %v, want %v", delays, tt.thenDelays)
+			}
+		})
+	}
+}
+
+func TestReplayer_RunSendError(t *testing.T) {
+	sendErr := errors.New("test")
+	err := NewReplayer(Config{}).Run(context.Background(), testEvents(t, event1, event2), func(event cloudevents.Event) error {
+		return sendErr
+	})
+	if err != sendErr {
+		t.Errorf("Replayer.Run() error = %v, want %v", err, sendErr)
+	}
+}
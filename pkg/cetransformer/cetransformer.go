@@ -2,15 +2,25 @@ package cetransformer
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/alitari/ce-go-template/pkg/transformer"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/google/uuid"
 )
 
+// idNamespace namespace of the derived event ids
+var idNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/alitari/ce-go-template"))
+
+// DeriveID a deterministic id of an event of the result type created from the source event, a redelivered source event gets the same id
+func DeriveID(sourceEvent *cloudevents.Event, resultType string) string {
+	return uuid.NewSHA1(idNamespace, []byte(sourceEvent.Source()+"\n"+sourceEvent.ID()+"\n"+resultType)).String()
+}
+
 // CloudEventTransformer bla
 type CloudEventTransformer struct {
 	transformer  *transformer.Transformer
+	extensions   *transformer.Transformer
 	resultType   string
 	resultSource string
 }
@@ -29,6 +39,20 @@ func NewCloudEventTransformer(ceTemplate, resultSource, resultType string, debug
 	return cet, nil
 }
 
+// NewCloudEventTransformerWithExtensions as NewCloudEventTransformer, the extensions template renders a json object with extensions of the result event, e.g. '{"tenant": {{ .data.tenant | quote }}}'
+func NewCloudEventTransformerWithExtensions(ceTemplate, extensionsTemplate, resultSource, resultType string, debug bool) (*CloudEventTransformer, error) {
+	cet, err := NewCloudEventTransformer(ceTemplate, resultSource, resultType, debug)
+	if err != nil || extensionsTemplate == "" {
+		return cet, err
+	}
+	extensions, err := transformer.NewTransformer(extensionsTemplate, nil, debug)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extensions template: %v", err)
+	}
+	cet.extensions = extensions
+	return cet, nil
+}
+
 // EventToMap Transform a cloudevent to input data
 func EventToMap(event *cloudevents.Event) map[string]interface{} {
 	evt := map[string]interface{}{}
@@ -70,15 +94,25 @@ func TransformBytesToEvent(eventMarshalled []byte, context cloudevents.EventCont
 	return &resultEvent, nil
 }
 
-// CreateEvent bla
+// CreateEvent transforms an empty event, the entries of an input map are additionally available in the template
 func (ct *CloudEventTransformer) CreateEvent(input interface{}) (*cloudevents.Event, error) {
 	ce := cloudevents.NewEvent()
-	return ct.TransformEvent(&ce)
+	templateInput := EventToMap(&ce)
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		for k, v := range inputMap {
+			templateInput[k] = v
+		}
+	}
+	return ct.transformEvent(&ce, templateInput)
 }
 
 // TransformEvent bla
 func (ct *CloudEventTransformer) TransformEvent(sourceEvent *cloudevents.Event) (*cloudevents.Event, error) {
-	resultEventBytes, err := ct.transformer.TransformInputToBytes(EventToMap(sourceEvent))
+	return ct.transformEvent(sourceEvent, EventToMap(sourceEvent))
+}
+
+func (ct *CloudEventTransformer) transformEvent(sourceEvent *cloudevents.Event, templateInput map[string]interface{}) (*cloudevents.Event, error) {
+	resultEventBytes, err := ct.transformer.TransformInputToBytes(templateInput)
 	if err != nil {
 		return nil, err
 	}
@@ -97,10 +131,36 @@ func (ct *CloudEventTransformer) TransformEvent(sourceEvent *cloudevents.Event)
 	} else {
 		resultEvent.SetSource(ct.resultSource)
 	}
+	if sourceEvent.ID() != "" {
+		resultEvent.SetID(DeriveID(sourceEvent, resultEvent.Type()))
+	}
+	if ct.extensions != nil {
+		if err := ct.setExtensions(resultEvent, templateInput); err != nil {
+			return nil, err
+		}
+	}
 
 	return resultEvent, nil
 }
 
+// setExtensions renders the extensions template, they replace extensions of the source event with the same name
+func (ct *CloudEventTransformer) setExtensions(event *cloudevents.Event, templateInput map[string]interface{}) error {
+	extensionsBytes, err := ct.extensions.TransformInputToBytes(templateInput)
+	if err != nil {
+		return err
+	}
+	extensions := map[string]interface{}{}
+	if err := json.Unmarshal(extensionsBytes, &extensions); err != nil {
+		return fmt.Errorf("extensions template must render a json object: %v", err)
+	}
+	for name, value := range extensions {
+		if err := event.Context.SetExtension(name, value); err != nil {
+			return fmt.Errorf("invalid extension '%s': %v", name, err)
+		}
+	}
+	return nil
+}
+
 // PredicateEvent bla
 func (ct *CloudEventTransformer) PredicateEvent(sourceEvent *cloudevents.Event) (bool, error) {
 	resultEventBytes, err := ct.transformer.TransformInputToBytes(EventToMap(sourceEvent))
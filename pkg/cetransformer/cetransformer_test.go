@@ -15,6 +15,7 @@ func TestTransformEvent(t *testing.T) {
 		name             string
 		givenTemplate    string
 		givenOnlyCreate  bool
+		givenInput       interface{}
 		givenEventType   string
 		givenEventSource string
 		whenEvent        cloudevents.Event
@@ -48,6 +49,13 @@ func TestTransformEvent(t *testing.T) {
 			whenEvent:       cloudevents.NewEvent(),
 			thenEvent:       NewEventWithJSONStringData(`{"name": "Alex"}`, "", ""),
 			thenError:       false},
+		{name: "createWithInput",
+			givenOnlyCreate: true,
+			givenInput:      map[string]interface{}{"schedule": map[string]interface{}{"count": 3}},
+			givenTemplate:   `{"count": {{ .schedule.count }}}`,
+			whenEvent:       cloudevents.NewEvent(),
+			thenEvent:       NewEventWithJSONStringData(`{"count": 3}`, "", ""),
+			thenError:       false},
 		{name: "constantType",
 			givenTemplate:  `{"name": "Alex"}`,
 			givenEventType: "danitype",
@@ -98,7 +106,7 @@ func TestTransformEvent(t *testing.T) {
 			}
 			var got *cloudevents.Event
 			if tt.givenOnlyCreate {
-				got, err = ct.CreateEvent(nil)
+				got, err = ct.CreateEvent(tt.givenInput)
 			} else {
 				got, err = ct.TransformEvent(&tt.whenEvent)
 			}
@@ -295,3 +303,63 @@ func TestCloudEventTransformer_TransformBytesToEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestCloudEventTransformer_derivedID(t *testing.T) {
+	ct, err := NewCloudEventTransformer("{{ toJson .data }}", "", "mapped", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := NewEventWithJSONStringData(`{"name": "Alex"}`)
+	first, err := ct.TransformEvent(&source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redelivered, _ := ct.TransformEvent(&source)
+	if first.ID() != redelivered.ID() || first.ID() == source.ID() {
+		t.Errorf("TransformEvent() ids = %s, %s, want equal ids different from source id %s", first.ID(), redelivered.ID(), source.ID())
+	}
+	other := NewEventWithJSONStringData(`{"name": "Alex"}`, "source", "type", "other")
+	if otherEvent, _ := ct.TransformEvent(&other); otherEvent.ID() == first.ID() {
+		t.Errorf("TransformEvent() id of other source event = %s, want a different id", otherEvent.ID())
+	}
+	created, _ := ct.CreateEvent(nil)
+	createdAgain, _ := ct.CreateEvent(nil)
+	if created.ID() == createdAgain.ID() {
+		t.Errorf("CreateEvent() ids are equal %s", created.ID())
+	}
+}
+
+func TestCloudEventTransformer_extensions(t *testing.T) {
+	tests := []struct {
+		name                    string
+		givenExtensionsTemplate string
+		whenExtensions          map[string]interface{}
+		thenExtensions          map[string]interface{}
+		thenWantErr             bool
+	}{
+		{name: "no template", whenExtensions: map[string]interface{}{"tenant": "a"}, thenExtensions: map[string]interface{}{"tenant": "a"}},
+		{name: "template replaces incoming extension", givenExtensionsTemplate: `{"tenant": {{ .data.tenant | quote }}, "priority": 1}`,
+			whenExtensions: map[string]interface{}{"tenant": "a", "region": "eu"}, thenExtensions: map[string]interface{}{"tenant": "b", "region": "eu", "priority": int32(1)}},
+		{name: "no json object", givenExtensionsTemplate: `["b"]`, thenWantErr: true},
+		{name: "invalid name", givenExtensionsTemplate: `{"ten-ant": "b"}`, thenWantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct, err := NewCloudEventTransformerWithExtensions("{{ toJson .data }}", tt.givenExtensionsTemplate, "", "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			source := NewEventWithJSONStringData(`{"tenant": "b"}`)
+			for name, value := range tt.whenExtensions {
+				source.SetExtension(name, value)
+			}
+			result, err := ct.TransformEvent(&source)
+			if (err != nil) != tt.thenWantErr {
+				t.Fatalf("TransformEvent() error = %v, wantErr %v", err, tt.thenWantErr)
+			}
+			if err == nil && !reflect.DeepEqual(result.Extensions(), tt.thenExtensions) {
+				t.Errorf("TransformEvent() extensions = %v, want %v", result.Extensions(), tt.thenExtensions)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -57,19 +58,26 @@ func createEventWithContext(context ...string) cloudevents.Event {
 	return event
 }
 
-// CompareEvents true if events are "equal"
-func CompareEvents(t *testing.T, message string, actualEvent, wantedEvent cloudevents.Event) bool {
+// DiffEvents describes the differences of source, type and data of two events, empty if they are "equal"
+func DiffEvents(actualEvent, wantedEvent cloudevents.Event) []string {
+	diffs := []string{}
 	if actualEvent.Source() != wantedEvent.Source() {
-		t.Errorf("%s. source not fit.  actual= '%s', want '%v'", message, actualEvent.Source(), wantedEvent.Source())
-		return false
+		diffs = append(diffs, fmt.Sprintf("source not fit.  actual= '%s', want '%v'", actualEvent.Source(), wantedEvent.Source()))
 	}
-
 	if actualEvent.Type() != wantedEvent.Type() {
-		t.Errorf("%s. type not fit.  actual= '%s', want '%s'", message, actualEvent.Type(), wantedEvent.Type())
-		return false
+		diffs = append(diffs, fmt.Sprintf("type not fit.  actual= '%s', want '%s'", actualEvent.Type(), wantedEvent.Type()))
 	}
 	if bytes.Compare(actualEvent.Data(), wantedEvent.Data()) != 0 {
-		t.Errorf("%s. payload not equal. actual = '%v', want '%v'", message, actualEvent, wantedEvent)
+		diffs = append(diffs, fmt.Sprintf("payload not equal. actual = '%s', want '%s'", actualEvent.Data(), wantedEvent.Data()))
+	}
+	return diffs
+}
+
+// CompareEvents true if events are "equal"
+func CompareEvents(t *testing.T, message string, actualEvent, wantedEvent cloudevents.Event) bool {
+	diffs := DiffEvents(actualEvent, wantedEvent)
+	if len(diffs) > 0 {
+		t.Errorf("%s. %s", message, diffs[0])
 		return false
 	}
 	return true
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v2"
+)
+
+// FileEnv environment variable with the name of the config file, if the flag --config is not set
+const FileEnv = "CONFIG_FILE"
+
+// Options of the configuration layer itself
+type Options struct {
+	// File yaml config file
+	File string
+	// PrintConfig print the effective configuration and exit
+	PrintConfig bool
+	// Validate parse the configuration and all templates and exit
+	Validate bool
+}
+
+// Key a configuration key of a spec
+type Key struct {
+	// Name name of the environment variable, e.g. CE_TEMPLATE
+	Name        string
+	Kind        string
+	Default     string
+	Description string
+}
+
+// Flag name of the command line flag, e.g. ce-template
+func (k Key) Flag() string {
+	return strings.ReplaceAll(strings.ToLower(k.Name), "_", "-")
+}
+
+var keysTemplate = template.Must(template.New("keys").Funcs(template.FuncMap{
+	"key": func(name, kind, def, desc string) (string, error) {
+		b, err := json.Marshal(Key{Name: name, Kind: kind, Default: def, Description: desc})
+		return string(b), err
+	},
+}).Parse(`{{ range . }}{{ key .Key .Field.Kind.String (.Tags.Get "default") (.Tags.Get "desc") }}
+{{ end }}`))
+
+// Keys configuration keys of an envconfig spec
+func Keys(spec interface{}) ([]Key, error) {
+	buf := &bytes.Buffer{}
+	if err := envconfig.Usaget("", spec, buf, keysTemplate); err != nil {
+		return nil, err
+	}
+	keys := []Key{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		key := Key{}
+		if err := decoder.Decode(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Load fills the envconfig spec. Flags take precedence over environment variables, which take precedence over the config file.
+func Load(name string, spec interface{}, args []string, output io.Writer) (Options, error) {
+	options := Options{}
+	keys, err := Keys(spec)
+	if err != nil {
+		return options, err
+	}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&options.File, "config", os.Getenv(FileEnv), "yaml config file with the configuration keys, e.g. 'CE_TEMPLATE: true'")
+	flags.BoolVar(&options.PrintConfig, "print-config", false, "print the effective configuration and exit")
+	flags.BoolVar(&options.Validate, "validate", false, "parse the configuration and all templates and exit")
+	flagValues := map[string]*flagValue{}
+	for _, key := range keys {
+		value := &flagValue{isBool: key.Kind == "bool"}
+		flagValues[key.Name] = value
+		flags.Var(value, key.Flag(), usage(key))
+	}
+	if err := flags.Parse(args); err != nil {
+		return options, err
+	}
+	if flags.NArg() > 0 {
+		return options, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	values := map[string]string{}
+	if options.File != "" {
+		if values, err = ReadFile(options.File, keys); err != nil {
+			return options, err
+		}
+	}
+	for key, value := range flagValues {
+		if value.set {
+			values[key] = value.value
+		}
+	}
+	for key, value := range values {
+		if _, inEnv := os.LookupEnv(key); inEnv && !flagValues[key].set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return options, err
+		}
+	}
+	return options, envconfig.Process("", spec)
+}
+
+func usage(key Key) string {
+	usage := fmt.Sprintf("env %s", key.Name)
+	if key.Description != "" {
+		usage = key.Description + ", " + usage
+	}
+	if key.Default != "" {
+		usage += fmt.Sprintf(" (default %q)", key.Default)
+	}
+	return usage
+}
+
+// flagValue remembers whether a flag is set, so that unset flags don't override the environment
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (fv *flagValue) String() string {
+	return fv.value
+}
+
+func (fv *flagValue) Set(value string) error {
+	fv.value, fv.set = value, true
+	return nil
+}
+
+func (fv *flagValue) IsBoolFlag() bool {
+	return fv.isBool
+}
+
+// ReadFile reads a yaml config file. Keys are environment variable names or flag names, lists and maps are converted to the envconfig format.
+func ReadFile(fileName string, keys []Key) (map[string]string, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config file '%s': %v", fileName, err)
+	}
+	known := map[string]bool{}
+	for _, key := range keys {
+		known[key.Name] = true
+	}
+	values := map[string]string{}
+	for k, v := range raw {
+		name := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		if !known[name] {
+			return nil, fmt.Errorf("unknown key '%s' in config file '%s'", k, fileName)
+		}
+		values[name] = envValue(v)
+	}
+	return values, nil
+}
+
+// envValue lists as "a,b" and maps as "k1:v1,k2:v2"
+func envValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		elements := []string{}
+		for _, e := range v {
+			elements = append(elements, envValue(e))
+		}
+		return strings.Join(elements, ",")
+	case map[interface{}]interface{}:
+		entries := []string{}
+		for k, e := range v {
+			entries = append(entries, fmt.Sprintf("%v:%s", k, envValue(e)))
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ",")
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+var printTemplate = template.Must(template.New("print").Funcs(template.FuncMap{
+	"value": func(v interface{}) string {
+		switch value := v.(type) {
+		case []string:
+			return strings.Join(value, ",")
+		case map[string]string:
+			entries := []string{}
+			for k, e := range value {
+				entries = append(entries, k+":"+e)
+			}
+			sort.Strings(entries)
+			return strings.Join(entries, ",")
+		case time.Time:
+			if value.IsZero() {
+				return ""
+			}
+			return value.Format(time.RFC3339)
+		default:
+			return fmt.Sprint(v)
+		}
+	},
+}).Parse(`{{ range . }}{{ .Key }}: {{ printf "%q" (value .Field.Interface) }}
+{{ end }}`))
+
+// Print writes the effective configuration of a loaded spec as yaml, which can be used as config file
+func Print(w io.Writer, spec interface{}) error {
+	return envconfig.Usaget("", spec, w, printTemplate)
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testSpec struct {
+	Verbose    bool              `default:"true"`
+	CeTemplate string            `split_words:"true" default:"{{ toJson .data }}" desc:"go template"`
+	Timeout    time.Duration     `default:"1000ms"`
+	Headers    []string          `split_words:"true"`
+	Metadata   map[string]string `split_words:"true"`
+	Sink       string            `envconfig:"K_SINK"`
+}
+
+func TestKeys(t *testing.T) {
+	keys, err := Keys(&testSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Key{
+		{Name: "VERBOSE", Kind: "bool", Default: "true"},
+		{Name: "CE_TEMPLATE", Kind: "string", Default: "{{ toJson .data }}", Description: "go template"},
+		{Name: "TIMEOUT", Kind: "int64", Default: "1000ms"},
+		{Name: "HEADERS", Kind: "slice"},
+		{Name: "METADATA", Kind: "map"},
+		{Name: "K_SINK", Kind: "string"},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Keys() = %v, want %v", keys, want)
+	}
+	if keys[1].Flag() != "ce-template" {
+		t.Errorf("Key.Flag() = %s, want ce-template", keys[1].Flag())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file, err := ioutil.TempFile("", "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("CE_TEMPLATE: fromfile\ntimeout: 2s\nk-sink: http://file\nheaders: [a, b]\nmetadata:\n  tenant: x\n")
+	file.Close()
+
+	tests := []struct {
+		name        string
+		givenEnv    map[string]string
+		whenArgs    []string
+		thenSpec    testSpec
+		thenOptions Options
+		thenError   bool
+	}{
+		{name: "defaults",
+			thenSpec: testSpec{Verbose: true, CeTemplate: "{{ toJson .data }}", Timeout: time.Second}},
+		{name: "env",
+			givenEnv: map[string]string{"CE_TEMPLATE": "fromenv", "K_SINK": "http://env"},
+			thenSpec: testSpec{Verbose: true, CeTemplate: "fromenv", Timeout: time.Second, Sink: "http://env"}},
+		{name: "flags override env",
+			givenEnv: map[string]string{"CE_TEMPLATE": "fromenv"},
+			whenArgs: []string{"--ce-template", "fromflag", "--verbose=false", "--validate"},
+			thenSpec: testSpec{Verbose: false, CeTemplate: "fromflag", Timeout: time.Second}, thenOptions: Options{Validate: true}},
+		{name: "file",
+			whenArgs:    []string{"--config", file.Name(), "--print-config"},
+			thenSpec:    testSpec{Verbose: true, CeTemplate: "fromfile", Timeout: 2 * time.Second, Sink: "http://file", Headers: []string{"a", "b"}, Metadata: map[string]string{"tenant": "x"}},
+			thenOptions: Options{File: file.Name(), PrintConfig: true}},
+		{name: "env overrides file",
+			givenEnv:    map[string]string{"CE_TEMPLATE": "fromenv"},
+			whenArgs:    []string{"--config", file.Name()},
+			thenSpec:    testSpec{Verbose: true, CeTemplate: "fromenv", Timeout: 2 * time.Second, Sink: "http://file", Headers: []string{"a", "b"}, Metadata: map[string]string{"tenant": "x"}},
+			thenOptions: Options{File: file.Name()}},
+		{name: "unknown flag",
+			whenArgs:  []string{"--unknown", "x"},
+			thenError: true},
+		{name: "invalid value",
+			whenArgs:  []string{"--timeout", "x"},
+			thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv()
+			for k, v := range tt.givenEnv {
+				os.Setenv(k, v)
+			}
+			spec := testSpec{}
+			options, err := Load("test", &spec, tt.whenArgs, ioutil.Discard)
+			if (err != nil) != tt.thenError {
+				t.Fatalf("Load() error = %v, thenError %v", err, tt.thenError)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(spec, tt.thenSpec) {
+				t.Errorf("Load() spec = %+v, want %+v", spec, tt.thenSpec)
+			}
+			if options != tt.thenOptions {
+				t.Errorf("Load() options = %+v, want %+v", options, tt.thenOptions)
+			}
+		})
+	}
+	unsetEnv()
+}
+
+func unsetEnv() {
+	for _, k := range []string{"VERBOSE", "CE_TEMPLATE", "TIMEOUT", "HEADERS", "METADATA", "K_SINK", FileEnv} {
+		os.Unsetenv(k)
+	}
+}
+
+func TestReadFile_unknownKey(t *testing.T) {
+	file, err := ioutil.TempFile("", "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("CE_TEMPLAT: typo\n")
+	file.Close()
+	if _, err := ReadFile(file.Name(), []Key{{Name: "CE_TEMPLATE"}}); err == nil {
+		t.Errorf("ReadFile() with unknown key must fail")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	spec := testSpec{Verbose: true, CeTemplate: `{"a": 1}`, Timeout: time.Second, Headers: []string{"a", "b"}, Metadata: map[string]string{"y": "2", "x": "1"}}
+	buf := &bytes.Buffer{}
+	if err := Print(buf, &spec); err != nil {
+		t.Fatal(err)
+	}
+	want := `VERBOSE: "true"
+CE_TEMPLATE: "{\"a\": 1}"
+TIMEOUT: "1s"
+HEADERS: "a,b"
+METADATA: "x:1,y:2"
+K_SINK: ""
+`
+	if buf.String() != want {
+		t.Errorf("Print() = %s, want %s", buf.String(), want)
+	}
+}
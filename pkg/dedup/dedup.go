@@ -0,0 +1,132 @@
+package dedup
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/logging"
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// Deduplicator drops events whose key was processed successfully within the ttl
+type Deduplicator struct {
+	store      Store
+	ttl        time.Duration
+	key        *transformer.Transformer
+	mu         sync.Mutex
+	pending    map[string]bool
+	duplicates uint64
+}
+
+// NewDeduplicator the key of an event is source and id, or rendered by the key template
+func NewDeduplicator(store Store, ttl time.Duration, keyTemplate string) (*Deduplicator, error) {
+	d := &Deduplicator{store: store, ttl: ttl, pending: map[string]bool{}}
+	if keyTemplate != "" {
+		key, err := transformer.NewTransformer(keyTemplate, nil, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key template: %v", err)
+		}
+		d.key = key
+	}
+	return d, nil
+}
+
+// Key of the event
+func (d *Deduplicator) Key(event cloudevents.Event) (string, error) {
+	if d.key == nil {
+		return event.Source() + "/" + event.ID(), nil
+	}
+	key, err := d.key.TransformInputToBytes(cetransformer.EventToMap(&event))
+	if err != nil {
+		return "", fmt.Errorf("key template: %v", err)
+	}
+	return string(key), nil
+}
+
+// Duplicates the number of dropped events
+func (d *Deduplicator) Duplicates() uint64 {
+	return atomic.LoadUint64(&d.duplicates)
+}
+
+// Middleware responds to a duplicate with status 200 without processing it. The key is only stored after the event was processed with a 2xx status, a duplicate which arrives while the event is processed gets status 409, so it is redelivered
+func (d *Deduplicator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "can't read body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		event, err := binding.ToEvent(r.Context(), cehttp.NewMessage(r.Header, ioutil.NopCloser(bytes.NewReader(body))))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, err := d.Key(*event)
+		if err != nil {
+			logging.ForEvent(*event).Warnf("event not deduplicated: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !d.begin(key) {
+			logging.ForEvent(*event).Infof("duplicate event is in process, key '%s'", key)
+			http.Error(w, "event with the same key is in process", http.StatusConflict)
+			return
+		}
+		defer d.end(key)
+		processed, err := d.store.Contains(key)
+		if err != nil {
+			logging.ForEvent(*event).Warnf("event not deduplicated: %v", err)
+		}
+		if processed {
+			atomic.AddUint64(&d.duplicates, 1)
+			logging.ForEvent(*event).Infof("duplicate event dropped, key '%s'", key)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		if sw.status < 300 {
+			if err := d.store.Add(key, d.ttl); err != nil {
+				logging.ForEvent(*event).Warnf("failed to store key '%s' of processed event: %v", key, err)
+			}
+		}
+	})
+}
+
+// begin marks the key as pending, false if an event with the key is in process
+func (d *Deduplicator) begin(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending[key] {
+		return false
+	}
+	d.pending[key] = true
+	return true
+}
+
+func (d *Deduplicator) end(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.pending, key)
+}
+
+// statusWriter remembers the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
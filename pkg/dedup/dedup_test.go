@@ -0,0 +1,124 @@
+package dedup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEventRequest(id, tenant string) *http.Request {
+	request := httptest.NewRequest("POST", "/", strings.NewReader(`{"tenant": "`+tenant+`"}`))
+	request.Header.Set("Ce-Id", id)
+	request.Header.Set("Ce-Source", "source")
+	request.Header.Set("Ce-Type", "type")
+	request.Header.Set("Ce-Specversion", "1.0")
+	request.Header.Set("Content-Type", "application/json")
+	return request
+}
+
+func TestDeduplicator_Middleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		givenKeyTemplate string
+		givenStatus      int
+		whenRequests     []*http.Request
+		thenProcessed    int
+	}{
+		{name: "duplicate id", givenStatus: 202, whenRequests: []*http.Request{newEventRequest("1", "a"), newEventRequest("1", "a"), newEventRequest("2", "a")},
+			thenProcessed: 2},
+		{name: "failed event is processed again", givenStatus: 500, whenRequests: []*http.Request{newEventRequest("1", "a"), newEventRequest("1", "a")},
+			thenProcessed: 2},
+		{name: "key template", givenKeyTemplate: "{{ .data.tenant }}", givenStatus: 202,
+			whenRequests: []*http.Request{newEventRequest("1", "a"), newEventRequest("2", "a"), newEventRequest("3", "b")}, thenProcessed: 2},
+		{name: "no cloud event", givenStatus: 400, whenRequests: []*http.Request{httptest.NewRequest("POST", "/", nil), httptest.NewRequest("POST", "/", nil)},
+			thenProcessed: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDeduplicator(NewMemoryStore(), time.Minute, tt.givenKeyTemplate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			processed := 0
+			handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				processed++
+				w.WriteHeader(tt.givenStatus)
+			}))
+			for _, request := range tt.whenRequests {
+				handler.ServeHTTP(httptest.NewRecorder(), request)
+			}
+			if processed != tt.thenProcessed {
+				t.Errorf("Deduplicator.Middleware() processed %d events, want %d", processed, tt.thenProcessed)
+			}
+			if duplicates := uint64(len(tt.whenRequests) - tt.thenProcessed); d.Duplicates() != duplicates {
+				t.Errorf("Deduplicator.Duplicates() = %d, want %d", d.Duplicates(), duplicates)
+			}
+		})
+	}
+}
+
+func TestDeduplicator_MiddlewareInProcess(t *testing.T) {
+	d, err := NewDeduplicator(NewMemoryStore(), time.Minute, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	status := http.StatusInternalServerError
+	handler := d.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if started != nil {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(status)
+	}))
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(first, newEventRequest("1", "a"))
+		close(done)
+	}()
+	<-started
+
+	duplicate := httptest.NewRecorder()
+	handler.ServeHTTP(duplicate, newEventRequest("1", "a"))
+	if duplicate.Code != http.StatusConflict {
+		t.Errorf("Deduplicator.Middleware() status of duplicate in process = %d, want %d", duplicate.Code, http.StatusConflict)
+	}
+	close(release)
+	<-done
+
+	started, status = nil, http.StatusAccepted
+	redelivered := httptest.NewRecorder()
+	handler.ServeHTTP(redelivered, newEventRequest("1", "a"))
+	if redelivered.Code != http.StatusAccepted {
+		t.Errorf("Deduplicator.Middleware() status of redelivered failed event = %d, want %d", redelivered.Code, http.StatusAccepted)
+	}
+	if d.Duplicates() != 0 {
+		t.Errorf("Deduplicator.Duplicates() = %d, want 0", d.Duplicates())
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	if ok, _ := store.Contains("a"); ok {
+		t.Error("MemoryStore.Contains() new key = true")
+	}
+	store.Add("a", time.Second)
+	if ok, _ := store.Contains("a"); !ok {
+		t.Error("MemoryStore.Contains() added key = false")
+	}
+	now = now.Add(2 * time.Second)
+	if ok, _ := store.Contains("a"); ok {
+		t.Error("MemoryStore.Contains() expired key = true")
+	}
+	store.Add("b", time.Second)
+	now = now.Add(2 * sweepInterval)
+	store.Add("c", time.Second)
+	if store.Len() != 1 {
+		t.Errorf("MemoryStore.Len() after sweep = %d, want 1", store.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package dedup
+
+import (
+	"sync"
+	"time"
+)
+
+// Store remembers keys of processed events for a ttl. An embedded store like bbolt or badger can implement it to survive restarts
+type Store interface {
+	// Contains true if the key is present and not expired
+	Contains(key string) (bool, error)
+	// Add the key of a processed event
+	Add(key string, ttl time.Duration) error
+}
+
+// sweepInterval expired keys of the memory store are removed at most once in this interval
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the keys in memory until they expire
+type MemoryStore struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: map[string]time.Time{}, lastSweep: time.Now(), now: time.Now}
+}
+
+// Contains true if the key is present and not expired
+func (ms *MemoryStore) Contains(key string) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	expires, ok := ms.keys[key]
+	return ok && ms.now().Before(expires), nil
+}
+
+// Add the key, the ttl of a present key is renewed
+func (ms *MemoryStore) Add(key string, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	now := ms.now()
+	ms.sweep(now)
+	ms.keys[key] = now.Add(ttl)
+	return nil
+}
+
+// Len the number of keys including expired keys which are not swept yet
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.keys)
+}
+
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.lastSweep) < sweepInterval {
+		return
+	}
+	for key, expires := range ms.keys {
+		if !now.Before(expires) {
+			delete(ms.keys, key)
+		}
+	}
+	ms.lastSweep = now
+}
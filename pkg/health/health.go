@@ -0,0 +1,208 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+)
+
+// Check returns an error if the checked dependency is not healthy
+type Check func() error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Gauge returns the current value of a metric
+type Gauge func() float64
+
+type namedGauge struct {
+	name  string
+	help  string
+	gauge Gauge
+}
+
+// Health collects liveness and readiness checks and gauges and serves them on `/healthz`, `/readyz` and `/metrics`
+type Health struct {
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+	gauges    []namedGauge
+	mux       *http.ServeMux
+}
+
+// Status the response of the endpoints, checks contains "ok" or the error of every check
+type Status struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// New without checks live and ready
+func New() *Health {
+	h := &Health{mux: http.NewServeMux()}
+	h.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) { writeStatus(w, h.Live()) })
+	h.mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) { writeStatus(w, h.Ready()) })
+	h.mux.HandleFunc("/metrics", h.writeMetrics)
+	return h
+}
+
+// AddLivenessCheck the process is restarted if the check fails
+func (h *Health) AddLivenessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck the process gets no events while the check fails
+func (h *Health) AddReadinessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, namedCheck{name: name, check: check})
+}
+
+// AddGauge the metric is served on `/metrics` in the prometheus text format. The name may contain labels, e.g. `steps_in{step="vip"}`, gauges of the same metric must be added one after another
+func (h *Health) AddGauge(name, help string, gauge Gauge) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.gauges = append(h.gauges, namedGauge{name: name, help: help, gauge: gauge})
+}
+
+func (h *Health) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metric := ""
+	for _, g := range h.gauges {
+		if name := strings.SplitN(g.name, "{", 2)[0]; name != metric {
+			metric = name
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, g.help, name)
+		}
+		fmt.Fprintf(w, "%s %v\n", g.name, g.gauge())
+	}
+}
+
+// Handle adds a handler to the admin server
+func (h *Health) Handle(pattern string, handler http.Handler) {
+	h.mux.Handle(pattern, handler)
+}
+
+// Live runs the liveness checks
+func (h *Health) Live() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return run(h.liveness)
+}
+
+// Ready runs the liveness and readiness checks
+func (h *Health) Ready() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return run(append(append([]namedCheck{}, h.liveness...), h.readiness...))
+}
+
+// ServeHTTP serves the endpoints of the admin server
+func (h *Health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Serve starts the admin server in the background
+func (h *Health) Serve(port int) *http.Server {
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: h}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("admin server failed: %v", err)
+		}
+	}()
+	return server
+}
+
+func run(checks []namedCheck) Status {
+	status := Status{Status: "ok", Checks: map[string]string{}}
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			status.Status = "failed"
+			status.Checks[c.name] = err.Error()
+		} else {
+			status.Checks[c.name] = "ok"
+		}
+	}
+	return status
+}
+
+func writeStatus(w http.ResponseWriter, status Status) {
+	w.Header().Set("Content-Type", "application/json")
+	if status.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// Flag a check which fails until it is set
+type Flag struct {
+	set    int32
+	reason string
+}
+
+// NewFlag the reason is the error of the check while the flag is not set
+func NewFlag(reason string) *Flag {
+	return &Flag{reason: reason}
+}
+
+// Set the check succeeds from now on
+func (f *Flag) Set() {
+	atomic.StoreInt32(&f.set, 1)
+}
+
+// Unset the check fails from now on
+func (f *Flag) Unset() {
+	atomic.StoreInt32(&f.set, 0)
+}
+
+// Check bla
+func (f *Flag) Check() error {
+	if atomic.LoadInt32(&f.set) == 0 {
+		return errors.New(f.reason)
+	}
+	return nil
+}
+
+// TCPCheck succeeds if a tcp connection to the address can be opened
+func TCPCheck(address string, timeout time.Duration) Check {
+	return func() error {
+		conn, err := net.DialTimeout("tcp", address, timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// SinkCheck succeeds if a tcp connection to the host of the sink url can be opened
+func SinkCheck(sink string, timeout time.Duration) (Check, error) {
+	sinkURL, err := url.Parse(sink)
+	if err != nil {
+		return nil, err
+	}
+	if sinkURL.Host == "" {
+		return nil, fmt.Errorf("sink '%s' has no host", sink)
+	}
+	address := sinkURL.Host
+	if sinkURL.Port() == "" {
+		port := "80"
+		if sinkURL.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(sinkURL.Hostname(), port)
+	}
+	return TCPCheck(address, timeout), nil
+}
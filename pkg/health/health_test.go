@@ -0,0 +1,126 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHealth_endpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		givenLiveness  map[string]error
+		givenReadiness map[string]error
+		whenPath       string
+		thenStatusCode int
+		thenStatus     Status
+	}{
+		{name: "live without checks", whenPath: "/healthz", thenStatusCode: 200, thenStatus: Status{Status: "ok", Checks: map[string]string{}}},
+		{name: "live ignores readiness", givenReadiness: map[string]error{"sink": errors.New("refused")}, whenPath: "/healthz",
+			thenStatusCode: 200, thenStatus: Status{Status: "ok", Checks: map[string]string{}}},
+		{name: "not live", givenLiveness: map[string]error{"deadlock": errors.New("stuck")}, whenPath: "/healthz",
+			thenStatusCode: 503, thenStatus: Status{Status: "failed", Checks: map[string]string{"deadlock": "stuck"}}},
+		{name: "ready", givenReadiness: map[string]error{"templates": nil, "receiver": nil}, whenPath: "/readyz",
+			thenStatusCode: 200, thenStatus: Status{Status: "ok", Checks: map[string]string{"templates": "ok", "receiver": "ok"}}},
+		{name: "not ready", givenLiveness: map[string]error{"alive": nil}, givenReadiness: map[string]error{"templates": nil, "sink": errors.New("refused")}, whenPath: "/readyz",
+			thenStatusCode: 503, thenStatus: Status{Status: "failed", Checks: map[string]string{"alive": "ok", "templates": "ok", "sink": "refused"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			for name, err := range tt.givenLiveness {
+				err := err
+				h.AddLivenessCheck(name, func() error { return err })
+			}
+			for name, err := range tt.givenReadiness {
+				err := err
+				h.AddReadinessCheck(name, func() error { return err })
+			}
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, httptest.NewRequest("GET", tt.whenPath, nil))
+			if recorder.Code != tt.thenStatusCode {
+				t.Errorf("Health.ServeHTTP() status code = %d, want %d", recorder.Code, tt.thenStatusCode)
+			}
+			status := Status{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(status, tt.thenStatus) {
+				t.Errorf("Health.ServeHTTP() status = %v, want %v", status, tt.thenStatus)
+			}
+		})
+	}
+}
+
+func TestFlag(t *testing.T) {
+	flag := NewFlag("not started")
+	if err := flag.Check(); err == nil || err.Error() != "not started" {
+		t.Errorf("Flag.Check() = %v, want 'not started'", err)
+	}
+	flag.Set()
+	if err := flag.Check(); err != nil {
+		t.Errorf("Flag.Check() after Set() = %v", err)
+	}
+	flag.Unset()
+	if err := flag.Check(); err == nil {
+		t.Error("Flag.Check() after Unset() = nil")
+	}
+}
+
+func TestSinkCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	check, err := SinkCheck(server.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check(); err != nil {
+		t.Errorf("SinkCheck() reachable sink = %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	check, err = SinkCheck("http://"+address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check(); err == nil {
+		t.Error("SinkCheck() unreachable sink = nil")
+	}
+
+	if _, err := SinkCheck("no-url", time.Second); err == nil {
+		t.Error("SinkCheck() sink without host = nil")
+	}
+}
+
+func TestHealth_metrics(t *testing.T) {
+	h := New()
+	h.AddGauge("outbox_depth", "number of events in the outbox", func() float64 { return 3 })
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	want := "# HELP outbox_depth number of events in the outbox\n# TYPE outbox_depth gauge\noutbox_depth 3\n"
+	if recorder.Body.String() != want {
+		t.Errorf("Health /metrics = %s, want %s", recorder.Body.String(), want)
+	}
+}
+
+func TestHealth_metricsWithLabels(t *testing.T) {
+	h := New()
+	h.AddGauge(`steps_in{step="vip"}`, "events processed by the step", func() float64 { return 3 })
+	h.AddGauge(`steps_in{step="gender"}`, "events processed by the step", func() float64 { return 2 })
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	want := "# HELP steps_in events processed by the step\n# TYPE steps_in gauge\nsteps_in{step=\"vip\"} 3\nsteps_in{step=\"gender\"} 2\n"
+	if recorder.Body.String() != want {
+		t.Errorf("Health /metrics = %s, want %s", recorder.Body.String(), want)
+	}
+}
@@ -0,0 +1,35 @@
+package limiter
+
+import (
+	"context"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// rateLimitedClient waits for a token before an event is sent, the key template gets the event like a mapper template
+type rateLimitedClient struct {
+	cloudevents.Client
+	limiter *RateLimiter
+}
+
+// NewRateLimitedClient the sends of the client are limited by the rate limiter
+func NewRateLimitedClient(client cloudevents.Client, limiter *RateLimiter) cloudevents.Client {
+	return &rateLimitedClient{Client: client, limiter: limiter}
+}
+
+func (c *rateLimitedClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	if err := c.limiter.Wait(ctx, cetransformer.EventToMap(&event)); err != nil {
+		return http.NewResult(429, "event not sent: %v", err)
+	}
+	return c.Client.Send(ctx, event)
+}
+
+func (c *rateLimitedClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	if err := c.limiter.Wait(ctx, cetransformer.EventToMap(&event)); err != nil {
+		return nil, http.NewResult(429, "event not sent: %v", err)
+	}
+	return c.Client.Request(ctx, event)
+}
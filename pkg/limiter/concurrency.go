@@ -0,0 +1,74 @@
+package limiter
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimiter limits the requests in flight, further requests wait in a queue until the queue timeout is over
+type ConcurrencyLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+	rejected     uint64
+}
+
+// NewConcurrencyLimiter for max requests in flight
+func NewConcurrencyLimiter(max int, queueTimeout time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{slots: make(chan struct{}, max), queueTimeout: queueTimeout}
+}
+
+// Acquire a slot, false if no slot is free within the queue timeout or the context is done
+func (cl *ConcurrencyLimiter) Acquire(ctx context.Context) bool {
+	select {
+	case cl.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if cl.queueTimeout > 0 {
+		timer := time.NewTimer(cl.queueTimeout)
+		defer timer.Stop()
+		select {
+		case cl.slots <- struct{}{}:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	atomic.AddUint64(&cl.rejected, 1)
+	return false
+}
+
+// Release the slot of an acquire
+func (cl *ConcurrencyLimiter) Release() {
+	<-cl.slots
+}
+
+// InFlight the number of acquired slots
+func (cl *ConcurrencyLimiter) InFlight() int {
+	return len(cl.slots)
+}
+
+// Rejected the number of requests without slot
+func (cl *ConcurrencyLimiter) Rejected() uint64 {
+	return atomic.LoadUint64(&cl.rejected)
+}
+
+// Middleware responds with status 429 and the Retry-After header if no slot is free
+func (cl *ConcurrencyLimiter) Middleware(retryAfter time.Duration) func(http.Handler) http.Handler {
+	seconds := strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter.Seconds()))))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cl.Acquire(r.Context()) {
+				w.Header().Set("Retry-After", seconds)
+				http.Error(w, "too many events in flight", http.StatusTooManyRequests)
+				return
+			}
+			defer cl.Release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package limiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter_Acquire(t *testing.T) {
+	tests := []struct {
+		name              string
+		givenQueueTimeout time.Duration
+		whenReleaseAfter  time.Duration
+		thenAcquired      bool
+	}{
+		{name: "reject without queue", thenAcquired: false},
+		{name: "queue timeout over", givenQueueTimeout: 10 * time.Millisecond, thenAcquired: false},
+		{name: "slot released while queued", givenQueueTimeout: time.Second, whenReleaseAfter: 10 * time.Millisecond, thenAcquired: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := NewConcurrencyLimiter(1, tt.givenQueueTimeout)
+			if !cl.Acquire(context.Background()) {
+				t.Fatal("ConcurrencyLimiter.Acquire() first slot = false")
+			}
+			if tt.whenReleaseAfter > 0 {
+				time.AfterFunc(tt.whenReleaseAfter, cl.Release)
+			}
+			if acquired := cl.Acquire(context.Background()); acquired != tt.thenAcquired {
+				t.Errorf("ConcurrencyLimiter.Acquire() = %v, want %v", acquired, tt.thenAcquired)
+			}
+			if !tt.thenAcquired && cl.Rejected() != 1 {
+				t.Errorf("ConcurrencyLimiter.Rejected() = %v, want 1", cl.Rejected())
+			}
+		})
+	}
+}
+
+func TestConcurrencyLimiter_Middleware(t *testing.T) {
+	cl := NewConcurrencyLimiter(1, 0)
+	handler := cl.Middleware(1500 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cl.InFlight() != 1 {
+			t.Errorf("ConcurrencyLimiter.InFlight() in handler = %v, want 1", cl.InFlight())
+		}
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	if recorder.Code != http.StatusOK || cl.InFlight() != 0 {
+		t.Errorf("Middleware status = %v, in flight = %v, want 200 and 0", recorder.Code, cl.InFlight())
+	}
+
+	cl.Acquire(context.Background())
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Middleware status = %v, want 429", recorder.Code)
+	}
+	if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("Middleware Retry-After = %v, want 2", retryAfter)
+	}
+}
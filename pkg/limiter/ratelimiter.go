@@ -0,0 +1,147 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/transformer"
+)
+
+// ErrRateLimited the context is done before a token is available
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// maxKeys buckets of keys which are full are removed above this number of keys
+const maxKeys = 10000
+
+// tokenBucket refills rate tokens per second up to burst
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter token buckets by a key. The key is rendered by a template, without template all calls share one bucket
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	key     *transformer.Transformer
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// NewRateLimiter with rate tokens per second, burst tokens at most and the key template
+func NewRateLimiter(rate float64, burst int, keyTemplate string) (*RateLimiter, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive, but is %v", rate)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	rl := &RateLimiter{rate: rate, burst: float64(burst), buckets: map[string]*tokenBucket{}, now: time.Now}
+	if keyTemplate != "" {
+		key, err := transformer.NewTransformer(keyTemplate, nil, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key template: %v", err)
+		}
+		rl.key = key
+	}
+	return rl, nil
+}
+
+// Key the rendered key template for the input
+func (rl *RateLimiter) Key(input interface{}) (string, error) {
+	if rl.key == nil {
+		return "", nil
+	}
+	key, err := rl.key.TransformInputToBytes(input)
+	if err != nil {
+		return "", fmt.Errorf("key template: %v", err)
+	}
+	return string(key), nil
+}
+
+// Wait until a token of the key of the input is available, ErrRateLimited if the context is done before
+func (rl *RateLimiter) Wait(ctx context.Context, input interface{}) error {
+	key, err := rl.Key(input)
+	if err != nil {
+		return err
+	}
+	delay, ok := rl.reserve(ctx, key)
+	if !ok {
+		return ErrRateLimited
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		rl.cancel(key)
+		return ErrRateLimited
+	}
+}
+
+// reserve takes a token and returns the delay until it is available, false if the context deadline is before
+func (rl *RateLimiter) reserve(ctx context.Context, key string) (time.Duration, bool) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := rl.now()
+	bucket := rl.bucket(key, now)
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0, true
+	}
+	delay := time.Duration(-bucket.tokens / rl.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		bucket.tokens++
+		return 0, false
+	}
+	return delay, true
+}
+
+// cancel returns the token of a reservation
+func (rl *RateLimiter) cancel(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if bucket, ok := rl.buckets[key]; ok {
+		bucket.tokens = minFloat(rl.burst, bucket.tokens+1)
+	}
+}
+
+// bucket of the key refilled until now
+func (rl *RateLimiter) bucket(key string, now time.Time) *tokenBucket {
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= maxKeys {
+			rl.removeFull(now)
+		}
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = bucket
+		return bucket
+	}
+	bucket.tokens = minFloat(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+	return bucket
+}
+
+// removeFull removes the buckets which would be full now, they are equal to new buckets
+func (rl *RateLimiter) removeFull(now time.Time) {
+	for key, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,90 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	tests := []struct {
+		name             string
+		givenRate        float64
+		givenBurst       int
+		givenKeyTemplate string
+		whenInputs       []map[string]interface{}
+		whenElapsed      time.Duration
+		thenErrors       []error
+	}{
+		{name: "burst", givenRate: 1, givenBurst: 2, whenInputs: []map[string]interface{}{{}, {}, {}},
+			thenErrors: []error{nil, nil, ErrRateLimited}},
+		{name: "refilled", givenRate: 10, givenBurst: 1, whenElapsed: 100 * time.Millisecond, whenInputs: []map[string]interface{}{{}, {}},
+			thenErrors: []error{nil, nil}},
+		{name: "per key", givenRate: 1, givenBurst: 1, givenKeyTemplate: "{{ .data.tenant }}",
+			whenInputs: []map[string]interface{}{{"data": map[string]interface{}{"tenant": "a"}}, {"data": map[string]interface{}{"tenant": "b"}}, {"data": map[string]interface{}{"tenant": "a"}}},
+			thenErrors: []error{nil, nil, ErrRateLimited}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl, err := NewRateLimiter(tt.givenRate, tt.givenBurst, tt.givenKeyTemplate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			rl.now = func() time.Time { return now }
+			for i, input := range tt.whenInputs {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				if err := rl.Wait(ctx, input); err != tt.thenErrors[i] {
+					t.Errorf("RateLimiter.Wait() call %d error = %v, want %v", i, err, tt.thenErrors[i])
+				}
+				cancel()
+				now = now.Add(tt.whenElapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_waits(t *testing.T) {
+	rl, err := NewRateLimiter(50, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := rl.Wait(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("RateLimiter.Wait() 3 tokens with rate 50 took %v, want at least 30ms", elapsed)
+	}
+}
+
+func TestNewRateLimiter_invalid(t *testing.T) {
+	if _, err := NewRateLimiter(0, 1, ""); err == nil {
+		t.Error("NewRateLimiter() rate 0 = nil error")
+	}
+	if _, err := NewRateLimiter(1, 1, "{{ .data"); err == nil {
+		t.Error("NewRateLimiter() invalid key template = nil error")
+	}
+}
+
+func TestRateLimitedClient_Send(t *testing.T) {
+	event := cetransformer.NewEventWithJSONStringData(`{"tenant": "a"}`)
+	rl, err := NewRateLimiter(0.001, 1, "{{ .data.tenant }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewRateLimitedClient(&cetransformer.CeClientMock{T: t, WantSend: true, WantSendEvent: event}, rl)
+	if result := client.Send(context.Background(), event); !cloudevents.IsACK(result) {
+		t.Errorf("Send() first event = %v", result)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if result := client.Send(ctx, event); cloudevents.IsACK(result) {
+		t.Error("Send() second event is not rate limited")
+	}
+}
@@ -0,0 +1,316 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehandler"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Config configuration of a load generator
+type Config struct {
+	// Rate target rate in events per second after the ramp-up
+	Rate float64
+	// Concurrency number of goroutines sending events
+	Concurrency int
+	// RampUp duration until the target rate is reached, no ramp-up if 0
+	RampUp time.Duration
+	// RampSteps number of steps of the ramp-up, a linear ramp-up if 0
+	RampSteps int
+	// Duration total duration of the load, unlimited if 0
+	Duration time.Duration
+	// MaxEvents maximum number of events, unlimited if 0
+	MaxEvents uint64
+	// Timeout send timeout of a single event
+	Timeout time.Duration
+}
+
+// Validate bla
+func (c Config) Validate() error {
+	if c.Rate <= 0 {
+		return fmt.Errorf("rate must be positive, but is %v", c.Rate)
+	}
+	if c.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be positive, but is %v", c.Concurrency)
+	}
+	if c.RampUp < 0 || c.RampSteps < 0 {
+		return fmt.Errorf("ramp-up must not be negative")
+	}
+	if c.Duration == 0 && c.MaxEvents == 0 {
+		return fmt.Errorf("duration or max events must be set")
+	}
+	return nil
+}
+
+// rate target rate after elapsed time
+func (c Config) rate(elapsed time.Duration) float64 {
+	if c.RampUp == 0 || elapsed >= c.RampUp {
+		return c.Rate
+	}
+	progress := float64(elapsed) / float64(c.RampUp)
+	if c.RampSteps > 0 {
+		step := int(progress*float64(c.RampSteps)) + 1
+		return c.Rate * float64(step) / float64(c.RampSteps)
+	}
+	return c.Rate * progress
+}
+
+// InputFunc creates the template input of an event with sequence number starting with 1
+type InputFunc func(sequence uint64, t time.Time) interface{}
+
+// Generator sends events with a target rate
+type Generator struct {
+	config   Config
+	producer cehandler.CeProducer
+	ceClient cloudevents.Client
+	sink     string
+	input    InputFunc
+	now      func() time.Time
+	interval time.Duration
+}
+
+// NewGenerator bla
+func NewGenerator(config Config, producer cehandler.CeProducer, ceClient cloudevents.Client, sink string, input InputFunc) (*Generator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Generator{config: config, producer: producer, ceClient: ceClient, sink: sink, input: input, now: time.Now, interval: 10 * time.Millisecond}, nil
+}
+
+// Run sends events until the duration is over, the maximum number of events is sent or the context is done
+func (g *Generator) Run(ctx context.Context) *Report {
+	if g.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Duration)
+		defer cancel()
+	}
+	jobs := make(chan uint64, g.config.Concurrency)
+	collector := newCollector()
+	wg := sync.WaitGroup{}
+	for i := 0; i < g.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sequence := range jobs {
+				collector.add(g.send(sequence))
+			}
+		}()
+	}
+	start := g.now()
+	g.dispatch(ctx, start, jobs)
+	close(jobs)
+	wg.Wait()
+	return collector.report(g.now().Sub(start))
+}
+
+// dispatch accumulates the events due according to the current rate
+func (g *Generator) dispatch(ctx context.Context, start time.Time, jobs chan<- uint64) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	var sequence uint64
+	due := 0.0
+	last := start
+	for {
+		now := g.now()
+		due += g.config.rate(now.Sub(start)) * now.Sub(last).Seconds()
+		last = now
+		for ; due >= 1; due-- {
+			if g.config.MaxEvents > 0 && sequence >= g.config.MaxEvents {
+				return
+			}
+			sequence++
+			select {
+			case jobs <- sequence:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type result struct {
+	latency time.Duration
+	err     error
+}
+
+func (g *Generator) send(sequence uint64) result {
+	var input interface{}
+	if g.input != nil {
+		input = g.input(sequence, g.now())
+	}
+	event, err := g.producer.CreateEvent(input)
+	if err != nil {
+		return result{err: fmt.Errorf("got error %v while producing event from input of type %T", err, input)}
+	}
+	ctx := cloudevents.ContextWithTarget(context.Background(), g.sink)
+	if g.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	sendResult := g.ceClient.Send(ctx, *event)
+	latency := time.Since(start)
+	if sendResult != nil && !cloudevents.IsACK(sendResult) {
+		return result{latency: latency, err: sendResult}
+	}
+	return result{latency: latency}
+}
+
+// Report summary of a load generator run
+type Report struct {
+	Sent     uint64
+	Failed   uint64
+	Duration time.Duration
+	// Rate events per second
+	Rate float64
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+	// Errors number of failed sends per error message
+	Errors map[string]uint64
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf(`
+Load report:
+====================================
+Sent: %d
+Failed: %d
+Duration: %v
+Rate: %.1f events/s
+Latency p50: %v p90: %v p99: %v max: %v
+Errors: %v`, r.Sent, r.Failed, r.Duration, r.Rate, r.P50, r.P90, r.P99, r.Max, r.Errors)
+}
+
+type collector struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	sent      uint64
+	failed    uint64
+	errors    map[string]uint64
+}
+
+func newCollector() *collector {
+	return &collector{errors: map[string]uint64{}}
+}
+
+func (c *collector) add(r result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.err != nil {
+		c.failed++
+		c.errors[r.err.Error()]++
+		return
+	}
+	c.sent++
+	c.latencies = append(c.latencies, r.latency)
+}
+
+func (c *collector) report(duration time.Duration) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sort.Slice(c.latencies, func(i, j int) bool { return c.latencies[i] < c.latencies[j] })
+	report := &Report{Sent: c.sent, Failed: c.failed, Duration: duration, Errors: c.errors,
+		P50: percentile(c.latencies, 50), P90: percentile(c.latencies, 90), P99: percentile(c.latencies, 99)}
+	if len(c.latencies) > 0 {
+		report.Max = c.latencies[len(c.latencies)-1]
+	}
+	if duration > 0 {
+		report.Rate = float64(c.sent+c.failed) / duration.Seconds()
+	}
+	return report
+}
+
+// percentile nearest rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Weighted a producer with a weight
+type Weighted struct {
+	Weight   int
+	Producer cehandler.CeProducer
+}
+
+// WeightedProducer chooses a producer randomly according to the weights
+type WeightedProducer struct {
+	producers []Weighted
+	total     int
+	mu        sync.Mutex
+	random    *rand.Rand
+}
+
+// NewWeightedProducer bla
+func NewWeightedProducer(producers []Weighted) (*WeightedProducer, error) {
+	if len(producers) == 0 {
+		return nil, fmt.Errorf("at least one producer is required")
+	}
+	total := 0
+	for i, p := range producers {
+		if p.Weight <= 0 {
+			return nil, fmt.Errorf("weight of producer %d must be positive, but is %d", i, p.Weight)
+		}
+		total += p.Weight
+	}
+	return &WeightedProducer{producers: producers, total: total, random: rand.New(rand.NewSource(time.Now().UnixNano()))}, nil
+}
+
+// CreateEvent bla
+func (wp *WeightedProducer) CreateEvent(input interface{}) (*cloudevents.Event, error) {
+	wp.mu.Lock()
+	n := wp.random.Intn(wp.total)
+	wp.mu.Unlock()
+	for _, p := range wp.producers {
+		if n < p.Weight {
+			return p.Producer.CreateEvent(input)
+		}
+		n -= p.Weight
+	}
+	return nil, fmt.Errorf("no producer for %d", n)
+}
+
+// WeightedTemplate template of an event with a weight, type is optional
+type WeightedTemplate struct {
+	Weight   int    `yaml:"weight"`
+	Template string `yaml:"template"`
+	Type     string `yaml:"type"`
+}
+
+// ReadTemplatesFile reads a json or yaml list of weighted templates
+func ReadTemplatesFile(fileName string) ([]WeightedTemplate, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	templates := []WeightedTemplate{}
+	if err := yaml.Unmarshal(content, &templates); err != nil {
+		return nil, fmt.Errorf("invalid templates file '%s': %v", fileName, err)
+	}
+	for i := range templates {
+		if templates[i].Weight == 0 {
+			templates[i].Weight = 1
+		}
+	}
+	return templates, nil
+}
@@ -0,0 +1,187 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+type producerMock struct {
+	name string
+}
+
+func (pm *producerMock) CreateEvent(input interface{}) (*cloudevents.Event, error) {
+	event := cetransformer.NewEventWithJSONStringData(`{"name": "` + pm.name + `"}`)
+	return &event, nil
+}
+
+type ceClientMock struct {
+	mu     sync.Mutex
+	events []cloudevents.Event
+	err    error
+}
+
+func (cm *ceClientMock) StartReceiver(ctx context.Context, fn interface{}) error {
+	return nil
+}
+
+func (cm *ceClientMock) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.events = append(cm.events, event)
+	return cm.err
+}
+
+func (cm *ceClientMock) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+	return nil, nil
+}
+
+func TestConfig_rate(t *testing.T) {
+	tests := []struct {
+		name        string
+		givenConfig Config
+		whenElapsed time.Duration
+		thenRate    float64
+	}{
+		{name: "no ramp-up", givenConfig: Config{Rate: 100}, whenElapsed: 0, thenRate: 100},
+		{name: "linear ramp-up", givenConfig: Config{Rate: 100, RampUp: 10 * time.Second}, whenElapsed: 5 * time.Second, thenRate: 50},
+		{name: "after ramp-up", givenConfig: Config{Rate: 100, RampUp: 10 * time.Second}, whenElapsed: 11 * time.Second, thenRate: 100},
+		{name: "first step", givenConfig: Config{Rate: 100, RampUp: 10 * time.Second, RampSteps: 4}, whenElapsed: time.Second, thenRate: 25},
+		{name: "third step", givenConfig: Config{Rate: 100, RampUp: 10 * time.Second, RampSteps: 4}, whenElapsed: 6 * time.Second, thenRate: 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.givenConfig.rate(tt.whenElapsed); got != tt.thenRate {
+				t.Errorf("Config.rate() = %v, want %v", got, tt.thenRate)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 1; i <= 200; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		name  string
+		given []time.Duration
+		when  int
+		then  time.Duration
+	}{
+		{name: "empty", given: []time.Duration{}, when: 50, then: 0},
+		{name: "single", given: []time.Duration{time.Second}, when: 99, then: time.Second},
+		{name: "p50", given: latencies, when: 50, then: 100 * time.Millisecond},
+		{name: "p99", given: latencies, when: 99, then: 198 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.given, tt.when); got != tt.then {
+				t.Errorf("percentile() = %v, want %v", got, tt.then)
+			}
+		})
+	}
+}
+
+func TestGenerator_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenConfig  Config
+		givenSendErr error
+		thenSent     uint64
+		thenFailed   uint64
+	}{
+		{name: "max events", givenConfig: Config{Rate: 1000, Concurrency: 4, MaxEvents: 20}, thenSent: 20},
+		{name: "send errors", givenConfig: Config{Rate: 1000, Concurrency: 2, MaxEvents: 5}, givenSendErr: errors.New("test"), thenFailed: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ceClient := &ceClientMock{err: tt.givenSendErr}
+			generator, err := NewGenerator(tt.givenConfig, &producerMock{name: "Alex"}, ceClient, "sink", func(sequence uint64, t time.Time) interface{} {
+				return sequence
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			report := generator.Run(context.Background())
+			if report.Sent != tt.thenSent || report.Failed != tt.thenFailed {
+				t.Errorf("Generator.Run() sent = %d failed = %d, want sent = %d failed = %d", report.Sent, report.Failed, tt.thenSent, tt.thenFailed)
+			}
+			if uint64(len(ceClient.events)) != tt.thenSent+tt.thenFailed {
+				t.Errorf("Generator.Run() sent %d events, want %d", len(ceClient.events), tt.thenSent+tt.thenFailed)
+			}
+		})
+	}
+}
+
+func TestGenerator_RunDuration(t *testing.T) {
+	ceClient := &ceClientMock{}
+	generator, err := NewGenerator(Config{Rate: 200, Concurrency: 2, Duration: 200 * time.Millisecond}, &producerMock{name: "Alex"}, ceClient, "sink", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := generator.Run(context.Background())
+	if report.Sent < 20 || report.Sent > 45 {
+		t.Errorf("Generator.Run() sent = %d, want about 40", report.Sent)
+	}
+}
+
+func TestNewGenerator_invalidConfig(t *testing.T) {
+	for _, config := range []Config{{Rate: 0, Concurrency: 1, MaxEvents: 1}, {Rate: 1, Concurrency: 0, MaxEvents: 1}, {Rate: 1, Concurrency: 1}} {
+		if _, err := NewGenerator(config, &producerMock{}, &ceClientMock{}, "sink", nil); err == nil {
+			t.Errorf("NewGenerator(%v) must fail", config)
+		}
+	}
+}
+
+func TestWeightedProducer_CreateEvent(t *testing.T) {
+	wp, err := NewWeightedProducer([]Weighted{{Weight: 3, Producer: &producerMock{name: "Alex"}}, {Weight: 1, Producer: &producerMock{name: "Dani"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wp.random = rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		event, err := wp.CreateEvent(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := map[string]interface{}{}
+		event.DataAs(&data)
+		counts[data["name"].(string)]++
+	}
+	if counts["Alex"] < 2800 || counts["Alex"] > 3200 {
+		t.Errorf("WeightedProducer.CreateEvent() distribution = %v, want about 3:1", counts)
+	}
+	if _, err := NewWeightedProducer([]Weighted{{Weight: 0, Producer: &producerMock{}}}); err == nil {
+		t.Errorf("NewWeightedProducer() with weight 0 must fail")
+	}
+}
+
+func TestReadTemplatesFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "templates*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("- weight: 3\n  template: '{\"name\": \"Alex\"}'\n- template: '{\"name\": \"Dani\"}'\n  type: dani\n")
+	file.Close()
+	got, err := ReadTemplatesFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []WeightedTemplate{{Weight: 3, Template: `{"name": "Alex"}`}, {Weight: 1, Template: `{"name": "Dani"}`, Type: "dani"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadTemplatesFile() = %v, want %v", got, want)
+	}
+}
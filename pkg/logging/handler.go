@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// LevelRequest the body of the level endpoint
+type LevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler serves the level of the logger, GET returns it, PUT changes it with a body like `{"level":"debug"}`
+func LevelHandler(logger *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			request := LevelRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			level, err := ParseLevel(request.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if level != logger.Level() {
+				logger.Infof("log level changed from %s to %s", logger.Level(), level)
+				logger.SetLevel(level)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LevelRequest{Level: logger.Level().String()})
+	})
+}
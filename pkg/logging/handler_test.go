@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		whenMethod     string
+		whenBody       string
+		thenStatusCode int
+		thenLevel      Level
+	}{
+		{name: "get", whenMethod: "GET", thenStatusCode: 200, thenLevel: LevelInfo},
+		{name: "put", whenMethod: "PUT", whenBody: `{"level": "debug"}`, thenStatusCode: 200, thenLevel: LevelDebug},
+		{name: "unknown level", whenMethod: "PUT", whenBody: `{"level": "trace"}`, thenStatusCode: 400, thenLevel: LevelInfo},
+		{name: "invalid body", whenMethod: "POST", whenBody: `debug`, thenStatusCode: 400, thenLevel: LevelInfo},
+		{name: "delete", whenMethod: "DELETE", thenStatusCode: 405, thenLevel: LevelInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := newTestLogger(t, FormatJSON, LevelInfo, nil)
+			recorder := httptest.NewRecorder()
+			LevelHandler(logger).ServeHTTP(recorder, httptest.NewRequest(tt.whenMethod, "/loglevel", strings.NewReader(tt.whenBody)))
+			if recorder.Code != tt.thenStatusCode {
+				t.Errorf("LevelHandler status code = %d, want %d, body: %s", recorder.Code, tt.thenStatusCode, recorder.Body.String())
+			}
+			if logger.Level() != tt.thenLevel {
+				t.Errorf("LevelHandler level = %v, want %v", logger.Level(), tt.thenLevel)
+			}
+			if tt.thenStatusCode == 200 && strings.TrimSpace(recorder.Body.String()) != `{"level":"`+tt.thenLevel.String()+`"}` {
+				t.Errorf("LevelHandler body = %s", recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Level severity of a log line
+type Level int32
+
+// levels in ascending severity
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel one of debug, info, warn, error
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level '%s', must be one of %s", name, strings.Join(levelNames, ", "))
+}
+
+// output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Fields additional key values of a log line
+type Fields map[string]interface{}
+
+// core is shared by a logger and all its derived loggers
+type core struct {
+	mu       sync.Mutex
+	out      io.Writer
+	json     bool
+	level    int32
+	redactor *Redactor
+	now      func() time.Time
+}
+
+// Logger writes leveled log lines with fields as text or json
+type Logger struct {
+	core   *core
+	fields Fields
+}
+
+// New a logger writing to out, format is text or json, payloads are redacted at the paths
+func New(out io.Writer, format string, level Level, redactPaths []string) (*Logger, error) {
+	if format != FormatText && format != FormatJSON {
+		return nil, fmt.Errorf("unknown log format '%s', must be %s or %s", format, FormatText, FormatJSON)
+	}
+	return &Logger{core: &core{out: out, json: format == FormatJSON, level: int32(level), redactor: NewRedactor(redactPaths), now: time.Now}}, nil
+}
+
+// Level the current level
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(&l.core.level))
+}
+
+// SetLevel changes the level of the logger and all derived loggers
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(&l.core.level, int32(level))
+}
+
+// Enabled true if lines of the level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With a logger adding the fields to every line
+func (l *Logger) With(fields Fields) *Logger {
+	merged := Fields{}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{core: l.core, fields: merged}
+}
+
+// ForEvent a logger adding id, type, source and trace id of the event to every line
+func (l *Logger) ForEvent(event cloudevents.Event) *Logger {
+	fields := Fields{"ce_id": event.ID(), "ce_type": event.Type(), "ce_source": event.Source()}
+	if traceID := TraceID(event); traceID != "" {
+		fields["trace_id"] = traceID
+	}
+	return l.With(fields)
+}
+
+// EventRef id, type and source of the event for error messages, the data of the event is not included
+func EventRef(event cloudevents.Event) string {
+	return fmt.Sprintf("id='%s' type='%s' source='%s'", event.ID(), event.Type(), event.Source())
+}
+
+// TraceID the trace id of the w3c traceparent extension of the event
+func TraceID(event cloudevents.Event) string {
+	traceparent, ok := event.Extensions()["traceparent"].(string)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 {
+		return ""
+	}
+	return parts[1]
+}
+
+// Debugf logs at debug level
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(LevelDebug, fmt.Sprintf(format, args...), nil)
+}
+
+// Infof logs at info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(LevelInfo, fmt.Sprintf(format, args...), nil)
+}
+
+// Warnf logs at warn level
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log(LevelWarn, fmt.Sprintf(format, args...), nil)
+}
+
+// Errorf logs at error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(LevelError, fmt.Sprintf(format, args...), nil)
+}
+
+// DebugPayload logs the redacted payload at debug level
+func (l *Logger) DebugPayload(msg string, payload []byte) {
+	if !l.Enabled(LevelDebug) {
+		return
+	}
+	l.log(LevelDebug, msg, Fields{"payload": string(l.core.redactor.Redact(payload))})
+}
+
+// DebugEvent logs the event with its redacted data at debug level
+func (l *Logger) DebugEvent(msg string, event cloudevents.Event) {
+	l.ForEvent(event).DebugPayload(msg, event.Data())
+}
+
+func (l *Logger) log(level Level, msg string, extra Fields) {
+	if !l.Enabled(level) {
+		return
+	}
+	fields := l.fields
+	if len(extra) > 0 {
+		fields = l.With(extra).fields
+	}
+	c := l.core
+	var line []byte
+	if c.json {
+		line = jsonLine(c.now(), level, msg, fields)
+	} else {
+		line = textLine(c.now(), level, msg, fields)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.out.Write(line)
+}
+
+func jsonLine(t time.Time, level Level, msg string, fields Fields) []byte {
+	entry := map[string]interface{}{}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	entry["time"] = t.UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{"time": t.UTC().Format(time.RFC3339Nano), "level": level.String(), "msg": msg, "error": err.Error()})
+	}
+	return append(line, '\n')
+}
+
+func textLine(t time.Time, level Level, msg string, fields Fields) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %-5s %s", t.Format("2006/01/02 15:04:05"), strings.ToUpper(level.String()), msg)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, " %s=%v", k, fields[k])
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// Writer writes every line at info level, used as output of the standard logger
+func (l *Logger) Writer() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		l.log(LevelInfo, strings.TrimRight(string(p), "\n"), nil)
+		return len(p), nil
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (w writerFunc) Write(p []byte) (int, error) {
+	return w(p)
+}
+
+var std atomic.Value
+
+func init() {
+	logger, _ := New(os.Stderr, FormatText, LevelInfo, nil)
+	std.Store(logger)
+}
+
+// Default the logger used by the package functions
+func Default() *Logger {
+	return std.Load().(*Logger)
+}
+
+// SetDefault replaces the logger used by the package functions
+func SetDefault(logger *Logger) {
+	std.Store(logger)
+}
+
+// ForEvent a default logger with the fields of the event
+func ForEvent(event cloudevents.Event) *Logger {
+	return Default().ForEvent(event)
+}
+
+// Debugf logs at debug level with the default logger
+func Debugf(format string, args ...interface{}) {
+	Default().Debugf(format, args...)
+}
+
+// Infof logs at info level with the default logger
+func Infof(format string, args ...interface{}) {
+	Default().Infof(format, args...)
+}
+
+// Warnf logs at warn level with the default logger
+func Warnf(format string, args ...interface{}) {
+	Default().Warnf(format, args...)
+}
+
+// Errorf logs at error level with the default logger
+func Errorf(format string, args ...interface{}) {
+	Default().Errorf(format, args...)
+}
+
+// DebugPayload logs the redacted payload at debug level with the default logger
+func DebugPayload(msg string, payload []byte) {
+	Default().DebugPayload(msg, payload)
+}
+
+// DebugEvent logs the event with its redacted data at debug level with the default logger
+func DebugEvent(msg string, event cloudevents.Event) {
+	Default().DebugEvent(msg, event)
+}
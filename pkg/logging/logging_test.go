@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func newTestLogger(t *testing.T, format string, level Level, redactPaths []string) (*Logger, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	logger, err := New(out, format, level, redactPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.core.now = func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return logger, out
+}
+
+func TestLogger_json(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetID("id")
+	event.SetType("type")
+	event.SetSource("source")
+	event.SetData(cloudevents.ApplicationJSON, []byte(`{"name": "Alex", "email": "alex@example.com"}`))
+	event.SetExtension("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	tests := []struct {
+		name        string
+		givenLevel  Level
+		givenRedact []string
+		whenLog     func(logger *Logger)
+		thenLine    map[string]interface{}
+	}{
+		{name: "info", givenLevel: LevelInfo, whenLog: func(logger *Logger) { logger.Infof("started %d", 1) },
+			thenLine: map[string]interface{}{"time": "2021-01-02T03:04:05Z", "level": "info", "msg": "started 1"}},
+		{name: "below level", givenLevel: LevelWarn, whenLog: func(logger *Logger) { logger.Infof("started") }},
+		{name: "fields", givenLevel: LevelInfo, whenLog: func(logger *Logger) { logger.With(Fields{"step": "1-filter"}).Errorf("failed") },
+			thenLine: map[string]interface{}{"time": "2021-01-02T03:04:05Z", "level": "error", "msg": "failed", "step": "1-filter"}},
+		{name: "event", givenLevel: LevelDebug, givenRedact: []string{"email"}, whenLog: func(logger *Logger) { logger.DebugEvent("sending event", event) },
+			thenLine: map[string]interface{}{"time": "2021-01-02T03:04:05Z", "level": "debug", "msg": "sending event",
+				"ce_id": "id", "ce_type": "type", "ce_source": "source", "trace_id": "0af7651916cd43dd8448eb211c80319c",
+				"payload": `{"email":"***","name":"Alex"}`}},
+		{name: "event below level", givenLevel: LevelInfo, whenLog: func(logger *Logger) { logger.DebugEvent("sending event", event) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, out := newTestLogger(t, FormatJSON, tt.givenLevel, tt.givenRedact)
+			tt.whenLog(logger)
+			if tt.thenLine == nil {
+				if out.Len() > 0 {
+					t.Errorf("Logger wrote '%s', want nothing", out.String())
+				}
+				return
+			}
+			line := map[string]interface{}{}
+			if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+				t.Fatalf("Logger wrote no json line '%s': %v", out.String(), err)
+			}
+			if !reflect.DeepEqual(line, tt.thenLine) {
+				t.Errorf("Logger line = %v, want %v", line, tt.thenLine)
+			}
+		})
+	}
+}
+
+func TestLogger_text(t *testing.T) {
+	logger, out := newTestLogger(t, FormatText, LevelInfo, nil)
+	logger.With(Fields{"b": 2, "a": 1}).Warnf("slow")
+	logger.Writer().Write([]byte("standard logger\n"))
+	want := "2021/01/02 03:04:05 WARN  slow a=1 b=2\n2021/01/02 03:04:05 INFO  standard logger\n"
+	if out.String() != want {
+		t.Errorf("Logger text = '%s', want '%s'", out.String(), want)
+	}
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	logger, out := newTestLogger(t, FormatText, LevelInfo, nil)
+	derived := logger.With(Fields{"a": 1})
+	logger.SetLevel(LevelDebug)
+	derived.Debugf("visible")
+	if out.Len() == 0 {
+		t.Error("derived logger doesn't use the changed level")
+	}
+}
+
+func TestNew_invalidFormat(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, "xml", LevelInfo, nil); err == nil {
+		t.Error("New() with format xml = nil error")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		whenName  string
+		thenLevel Level
+		thenError bool
+	}{
+		{whenName: "debug", thenLevel: LevelDebug},
+		{whenName: "WARN", thenLevel: LevelWarn},
+		{whenName: "error", thenLevel: LevelError},
+		{whenName: "trace", thenLevel: LevelInfo, thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.whenName, func(t *testing.T) {
+			level, err := ParseLevel(tt.whenName)
+			if (err != nil) != tt.thenError {
+				t.Errorf("ParseLevel() error = %v, want error %v", err, tt.thenError)
+			}
+			if level != tt.thenLevel {
+				t.Errorf("ParseLevel() = %v, want %v", level, tt.thenLevel)
+			}
+		})
+	}
+}
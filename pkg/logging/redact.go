@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Mask replaces redacted values
+const Mask = "***"
+
+// notJSON replaces payloads which can't be redacted
+const notJSON = "<redacted, payload is not json>"
+
+// Redactor masks values of json payloads. A path like `user.email` is matched at every level of the payload, `*` matches every key or array element.
+type Redactor struct {
+	paths [][]string
+}
+
+// NewRedactor for the dot separated paths
+func NewRedactor(paths []string) *Redactor {
+	r := &Redactor{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			r.paths = append(r.paths, strings.Split(path, "."))
+		}
+	}
+	return r
+}
+
+// Redact the payload with masked values. Without paths the payload is unchanged, a payload which is not json is replaced completely
+func (r *Redactor) Redact(payload []byte) []byte {
+	if len(r.paths) == 0 || len(payload) == 0 {
+		return payload
+	}
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return []byte(notJSON)
+	}
+	redacted, err := json.Marshal(r.walk(value))
+	if err != nil {
+		return []byte(notJSON)
+	}
+	return redacted
+}
+
+// walk applies all paths at this value and all nested values
+func (r *Redactor) walk(value interface{}) interface{} {
+	for _, path := range r.paths {
+		value = mask(value, path)
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = r.walk(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.walk(child)
+		}
+	}
+	return value
+}
+
+// mask the value at the path relative to value
+func mask(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return Mask
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if path[0] == "*" || path[0] == key {
+				v[key] = mask(child, path[1:])
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if path[0] == "*" || path[0] == strconv.Itoa(i) {
+				v[i] = mask(child, path[1:])
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,33 @@
+package logging
+
+import "testing"
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct {
+		name        string
+		givenPaths  []string
+		whenPayload string
+		thenPayload string
+	}{
+		{name: "no paths", whenPayload: `no json`, thenPayload: `no json`},
+		{name: "top level", givenPaths: []string{"email"}, whenPayload: `{"email": "alex@example.com", "name": "Alex"}`,
+			thenPayload: `{"email":"***","name":"Alex"}`},
+		{name: "nested at every level", givenPaths: []string{"user.email"}, whenPayload: `{"data": {"user": {"email": "a@b.c", "id": 1}}}`,
+			thenPayload: `{"data":{"user":{"email":"***","id":1}}}`},
+		{name: "object value", givenPaths: []string{"address"}, whenPayload: `{"address": {"city": "Berlin"}}`,
+			thenPayload: `{"address":"***"}`},
+		{name: "wildcard", givenPaths: []string{"users.*.name"}, whenPayload: `{"users": [{"name": "Alex"}, {"name": "Bob", "id": 2}]}`,
+			thenPayload: `{"users":[{"name":"***"},{"id":2,"name":"***"}]}`},
+		{name: "array index", givenPaths: []string{"cards.0"}, whenPayload: `{"cards": ["1234", "5678"]}`,
+			thenPayload: `{"cards":["***","5678"]}`},
+		{name: "null stays null", givenPaths: []string{"email"}, whenPayload: `{"email": null}`, thenPayload: `{"email":null}`},
+		{name: "not json", givenPaths: []string{"email"}, whenPayload: `email=alex@example.com`, thenPayload: notJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if payload := string(NewRedactor(tt.givenPaths).Redact([]byte(tt.whenPayload))); payload != tt.thenPayload {
+				t.Errorf("Redactor.Redact() = %s, want %s", payload, tt.thenPayload)
+			}
+		})
+	}
+}
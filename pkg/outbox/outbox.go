@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// fileSuffix of the files of queued events, the name is the sequence number
+const fileSuffix = ".json"
+
+// corruptSuffix files which can't be read are renamed with this suffix and skipped
+const corruptSuffix = ".corrupt"
+
+// SendFunc delivers an event, an error wrapped by Permanent drops the event
+type SendFunc func(event cloudevents.Event) error
+
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string {
+	return pe.err.Error()
+}
+
+// Permanent the delivery of the event will never succeed, it is removed from the outbox without retry
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent true if the error is wrapped by Permanent
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// Outbox a queue of events in a directory, one file per event. Events are delivered in order by Run and removed after delivery
+type Outbox struct {
+	mu         sync.Mutex
+	dir        string
+	files      []string
+	sequence   uint64
+	notify     chan struct{}
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Open the outbox in the directory, events of a previous process are delivered again
+func Open(dir string, minBackoff, maxBackoff time.Duration) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("can't create outbox directory: %v", err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read outbox directory: %v", err)
+	}
+	o := &Outbox{dir: dir, notify: make(chan struct{}, 1), minBackoff: minBackoff, maxBackoff: maxBackoff}
+	for _, entry := range entries {
+		if isTemp(entry.Name()) {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, fmt.Errorf("can't remove temporary outbox file: %v", err)
+			}
+			continue
+		}
+		sequence, ok := parseName(entry.Name())
+		if !ok {
+			continue
+		}
+		o.files = append(o.files, entry.Name())
+		if sequence > o.sequence {
+			o.sequence = sequence
+		}
+	}
+	sort.Strings(o.files)
+	return o, nil
+}
+
+// isTemp a file of an event which was not completely written, e.g. because the process was killed
+func isTemp(name string) bool {
+	_, ok := parseName(strings.TrimPrefix(name, "."))
+	return strings.HasPrefix(name, ".") && ok
+}
+
+func parseName(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, fileSuffix) {
+		return 0, false
+	}
+	sequence, err := strconv.ParseUint(strings.TrimSuffix(name, fileSuffix), 10, 64)
+	return sequence, err == nil
+}
+
+// Add writes the event to the outbox, it is delivered in the background
+func (o *Outbox) Add(event cloudevents.Event) error {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("can't marshal event: %v", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sequence++
+	name := fmt.Sprintf("%020d%s", o.sequence, fileSuffix)
+	tmp, path := filepath.Join(o.dir, "."+name), filepath.Join(o.dir, name)
+	if err := writeSynced(tmp, content); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("can't write event to outbox: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("can't write event to outbox: %v", err)
+	}
+	if err := syncDir(o.dir); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("can't write event to outbox: %v", err)
+	}
+	o.files = append(o.files, name)
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// writeSynced writes the content to the file and flushes it to the disk
+func writeSynced(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir flushes the directory to the disk, so a renamed file survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// Depth the number of events in the outbox
+func (o *Outbox) Depth() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.files)
+}
+
+// Run delivers the events in order until the context is done. A failed delivery is retried with exponential backoff
+func (o *Outbox) Run(ctx context.Context, send SendFunc) error {
+	backoff := o.minBackoff
+	for {
+		name, ok := o.next()
+		if !ok {
+			select {
+			case <-o.notify:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		event, err := o.read(name)
+		if err != nil {
+			logging.Errorf("outbox file '%s' skipped: %v", name, err)
+			o.remove(name, corruptSuffix)
+			continue
+		}
+		if err := send(event); err != nil {
+			if IsPermanent(err) {
+				logging.ForEvent(event).Errorf("event removed from outbox: %v", err)
+				o.remove(name, "")
+				continue
+			}
+			logging.ForEvent(event).Warnf("outbox delivery failed, retry in %v: %v", backoff, err)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+			if backoff *= 2; backoff > o.maxBackoff {
+				backoff = o.maxBackoff
+			}
+			continue
+		}
+		o.remove(name, "")
+		backoff = o.minBackoff
+	}
+}
+
+func (o *Outbox) next() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.files) == 0 {
+		return "", false
+	}
+	return o.files[0], true
+}
+
+func (o *Outbox) read(name string) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	content, err := ioutil.ReadFile(filepath.Join(o.dir, name))
+	if err != nil {
+		return event, err
+	}
+	err = json.Unmarshal(content, &event)
+	return event, err
+}
+
+// remove the first file, with a suffix it is renamed instead
+func (o *Outbox) remove(name, suffix string) {
+	path := filepath.Join(o.dir, name)
+	var err error
+	if suffix == "" {
+		err = os.Remove(path)
+	} else {
+		err = os.Rename(path, path+suffix)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		logging.Errorf("can't remove outbox file '%s': %v", name, err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.files = o.files[1:]
+}
@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestOutbox_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenFailures map[string]error
+		whenEvents    []string
+		thenDelivered []string
+		thenCorrupt   int
+	}{
+		{name: "in order", whenEvents: []string{"1", "2", "3"}, thenDelivered: []string{"1", "2", "3"}},
+		{name: "retry", givenFailures: map[string]error{"1": errors.New("unavailable")}, whenEvents: []string{"1", "2"},
+			thenDelivered: []string{"1", "2"}},
+		{name: "permanent error", givenFailures: map[string]error{"1": Permanent(errors.New("bad request"))}, whenEvents: []string{"1", "2"},
+			thenDelivered: []string{"2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			outbox, err := Open(dir, time.Millisecond, 10*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range tt.whenEvents {
+				if err := outbox.Add(cetransformer.NewEventWithJSONStringData(`{"n": 1}`, "source", "type", id)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if outbox.Depth() != len(tt.whenEvents) {
+				t.Errorf("Outbox.Depth() = %d, want %d", outbox.Depth(), len(tt.whenEvents))
+			}
+			delivered := []string{}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			outbox.Run(ctx, func(event cloudevents.Event) error {
+				if err := tt.givenFailures[event.ID()]; err != nil {
+					delete(tt.givenFailures, event.ID())
+					return err
+				}
+				delivered = append(delivered, event.ID())
+				if len(delivered) == len(tt.thenDelivered) {
+					cancel()
+				}
+				return nil
+			})
+			if len(delivered) != len(tt.thenDelivered) {
+				t.Fatalf("Outbox.Run() delivered %v, want %v", delivered, tt.thenDelivered)
+			}
+			for i := range delivered {
+				if delivered[i] != tt.thenDelivered[i] {
+					t.Errorf("Outbox.Run() delivered %v, want %v", delivered, tt.thenDelivered)
+				}
+			}
+			if outbox.Depth() != 0 {
+				t.Errorf("Outbox.Depth() after delivery = %d", outbox.Depth())
+			}
+			if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+				t.Errorf("outbox directory contains %d files after delivery", len(files))
+			}
+		})
+	}
+}
+
+func TestOpen_restart(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	outbox, err := Open(dir, time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.Add(cetransformer.NewEventWithJSONStringData(`{"n": 1}`, "source", "type", "1"))
+	outbox.Add(cetransformer.NewEventWithJSONStringData(`{"n": 2}`, "source", "type", "2"))
+	ioutil.WriteFile(filepath.Join(dir, "00000000000000000000.json"), []byte("no event"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "other.txt"), []byte("ignored"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, ".00000000000000000003.json"), []byte(`{"specversion": "1.0", "id": "partly`), 0644)
+
+	restarted, err := Open(dir, time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Depth() != 3 {
+		t.Fatalf("Outbox.Depth() after restart = %d, want 3", restarted.Depth())
+	}
+	restarted.Add(cetransformer.NewEventWithJSONStringData(`{"n": 3}`, "source", "type", "3"))
+	delivered := []string{}
+	ctx, cancel := context.WithCancel(context.Background())
+	restarted.Run(ctx, func(event cloudevents.Event) error {
+		delivered = append(delivered, event.ID())
+		if len(delivered) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if len(delivered) != 3 || delivered[0] != "1" || delivered[2] != "3" {
+		t.Errorf("Outbox.Run() after restart delivered %v, want [1 2 3]", delivered)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00000000000000000000.json"+corruptSuffix)); err != nil {
+		t.Errorf("corrupt file is not renamed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".00000000000000000003.json")); !os.IsNotExist(err) {
+		t.Errorf("temporary file is not removed: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Mode which transformation is rendered
+type Mode string
+
+const (
+	// Mapper renders CE_TEMPLATE to an event like the mapper
+	Mapper Mode = "mapper"
+	// Filter renders CE_TEMPLATE to a verdict like the filter
+	Filter Mode = "filter"
+	// HTTPClientMapper renders request and response templates to an event like the http-client-mapper
+	HTTPClientMapper Mode = "http-client-mapper"
+	// HTTPClientFilter renders request and response templates to a verdict like the http-client-filter
+	HTTPClientFilter Mode = "http-client-filter"
+)
+
+// Options of the rendering
+type Options struct {
+	Mode Mode
+	// Template CE_TEMPLATE in mapper and filter mode, RESPONSE_TEMPLATE in http modes
+	Template string
+	// Source, Type of the outgoing event in mapper mode
+	Source string
+	Type   string
+	// RequestTemplate, Steps REQUEST_TEMPLATE or REQUEST_STEPS in http modes
+	RequestTemplate string
+	Steps           []cehttpclienttransformer.Step
+	JSONBody        bool
+	// GraphQL GRAPHQL_* in http modes, the query is sent instead of the request template
+	GraphQL *cehttpclienttransformer.GraphQL
+	// MockResponses raw http responses which are returned in this order for the requests, the last one is repeated
+	MockResponses []string
+}
+
+// Result of the rendering
+type Result struct {
+	// Event outgoing event in mapper modes
+	Event *cloudevents.Event
+	// Verdict in filter modes
+	Verdict bool
+	// Requests rendered http requests in http modes
+	Requests []string
+}
+
+// Output event as indented json or the verdict
+func (r Result) Output(mode Mode) ([]byte, error) {
+	if mode == Filter || mode == HTTPClientFilter {
+		return []byte(fmt.Sprint(r.Verdict)), nil
+	}
+	return json.MarshalIndent(r.Event, "", "  ")
+}
+
+// Render transforms the event with the same transformers as the commands, http requests are not sent but answered by the mock responses
+func Render(options Options, event cloudevents.Event) (*Result, error) {
+	result := &Result{}
+	switch options.Mode {
+	case Mapper, Filter:
+		ct, err := cetransformer.NewCloudEventTransformer(options.Template, options.Source, options.Type, false)
+		if err != nil {
+			return nil, err
+		}
+		if options.Mode == Filter {
+			result.Verdict, err = ct.PredicateEvent(&event)
+		} else {
+			result.Event, err = ct.TransformEvent(&event)
+		}
+		return result, err
+	case HTTPClientMapper, HTTPClientFilter:
+		mock := &mockSenders{responses: options.MockResponses}
+		steps := options.Steps
+		if len(steps) == 0 && options.GraphQL == nil {
+			steps = []cehttpclienttransformer.Step{{Name: "request", RequestTemplate: options.RequestTemplate}}
+		}
+		ct, err := cehttpclienttransformer.NewCeHTTPClientTransformerWithConfig(cehttpclienttransformer.Config{SenderCreator: mock.create,
+			Steps: steps, ResponseTemplate: options.Template, Timeout: time.Second, JSONBody: options.JSONBody, GraphQL: options.GraphQL})
+		if err != nil {
+			return nil, err
+		}
+		if options.Mode == HTTPClientFilter {
+			result.Verdict, err = ct.PredicateEvent(&event)
+		} else {
+			result.Event, err = ct.TransformEvent(&event)
+		}
+		result.Requests = mock.requests
+		return result, err
+	default:
+		return nil, fmt.Errorf("unknown mode '%s', expected one of %s, %s, %s, %s", options.Mode, Mapper, Filter, HTTPClientMapper, HTTPClientFilter)
+	}
+}
+
+// NewGraphQL the GraphQL config with the query of the file, nil without url
+func NewGraphQL(url, queryFile, variablesTemplate string, headers map[string]string, failOnErrors bool) (*cehttpclienttransformer.GraphQL, error) {
+	if url == "" {
+		return nil, nil
+	}
+	query, err := ioutil.ReadFile(queryFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read GraphQL query file: %v", err)
+	}
+	return &cehttpclienttransformer.GraphQL{URL: url, Query: string(query), VariablesTemplate: variablesTemplate, Headers: headers, FailOnErrors: failOnErrors}, nil
+}
+
+// ValueOrFile the content of the file without final line break if set, the value otherwise. The final line break of a file would be part of the output
+func ValueOrFile(value, fileName string) (string, error) {
+	if fileName == "" {
+		return value, nil
+	}
+	content, err := ioutil.ReadFile(fileName)
+	return strings.TrimRight(string(content), "\r\n"), err
+}
+
+// ReadEvent reads a cloud event in structured mode. Json without specversion is the data of a new event.
+func ReadEvent(content []byte) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	probe := map[string]interface{}{}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return event, fmt.Errorf("invalid event: %v", err)
+	}
+	if _, ok := probe["specversion"]; ok {
+		err := json.Unmarshal(content, &event)
+		return event, err
+	}
+	event.SetID("render")
+	event.SetSource("render")
+	event.SetType("render")
+	err := event.SetData(cloudevents.ApplicationJSON, probe)
+	return event, err
+}
+
+// ParseMockResponse parses a raw http response, a response without status line is the body of a 200 response.
+// The content type of a body starting with '{' or '[' is application/json.
+func ParseMockResponse(raw string) (*http.Response, error) {
+	if !strings.HasPrefix(raw, "HTTP/") {
+		header := http.Header{}
+		trimmed := strings.TrimSpace(raw)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			header.Set("Content-Type", "application/json")
+		}
+		return &http.Response{Status: "200 OK", StatusCode: 200, Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1, Header: header,
+			Body: ioutil.NopCloser(strings.NewReader(raw)), ContentLength: int64(len(raw))}, nil
+	}
+	return http.ReadResponse(bufio.NewReader(strings.NewReader(raw)), nil)
+}
+
+// mockSenders records the rendered requests and answers them with mock responses
+type mockSenders struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []string
+}
+
+type mockSender struct {
+	request  *http.Request
+	response string
+}
+
+func (ms *mockSenders) create(protocol string, timeout time.Duration, debug bool) (cehttpclienttransformer.HTTPSender, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.requests = append(ms.requests, protocol)
+	request, err := cehttpclienttransformer.ParseHTTPRequest(protocol)
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{}"
+	if len(ms.responses) > 0 {
+		i := len(ms.requests) - 1
+		if i >= len(ms.responses) {
+			i = len(ms.responses) - 1
+		}
+		response = ms.responses[i]
+	}
+	return &mockSender{request: request, response: response}, nil
+}
+
+func (s *mockSender) Send() (*http.Response, error) {
+	response, err := ParseMockResponse(s.response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock response: %v", err)
+	}
+	if response.Body != nil {
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock response: %v", err)
+		}
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+		response.ContentLength = int64(len(body))
+	}
+	response.Request = s.request
+	return response, nil
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+)
+
+func TestRender(t *testing.T) {
+	inputEvent := cetransformer.NewEventWithJSONStringData(`{"name": "Alex"}`)
+	tests := []struct {
+		name         string
+		givenOptions Options
+		thenData     string
+		thenVerdict  bool
+		thenRequests []string
+		thenError    bool
+	}{
+		{name: "mapper",
+			givenOptions: Options{Mode: Mapper, Template: `{"name": {{ .data.name | upper | quote }}}`},
+			thenData:     `{"name":"ALEX"}`},
+		{name: "filter",
+			givenOptions: Options{Mode: Filter, Template: `{{ eq .data.name "Alex" }}`},
+			thenVerdict:  true},
+		{name: "http client mapper with default response",
+			givenOptions: Options{Mode: HTTPClientMapper, RequestTemplate: `GET http://customer/{{ .data.name }}`, Template: `{"status": {{ .httpresponse.statusCode }}}`, JSONBody: true},
+			thenData:     `{"status":200}`,
+			thenRequests: []string{"GET http://customer/Alex"}},
+		{name: "http client mapper with mock response",
+			givenOptions: Options{Mode: HTTPClientMapper, RequestTemplate: `GET http://customer/{{ .data.name }}`, Template: `{"id": {{ .httpresponse.body.id }}}`, JSONBody: true,
+				MockResponses: []string{"HTTP/1.1 201 Created\nContent-Type: application/json\n\n{\"id\": 7}"}},
+			thenData:     `{"id":7}`,
+			thenRequests: []string{"GET http://customer/Alex"}},
+		{name: "http client filter with steps and body mocks",
+			givenOptions: Options{Mode: HTTPClientFilter, Template: `{{ eq .steps.orders.body.count 3.0 }}`, JSONBody: true,
+				Steps: []cehttpclienttransformer.Step{
+					{Name: "customer", RequestTemplate: `GET http://customer/{{ .data.name }}`},
+					{Name: "orders", RequestTemplate: `GET http://orders/{{ .steps.customer.body.id }}`}},
+				MockResponses: []string{`{"id": 7}`, `{"count": 3}`}},
+			thenVerdict:  true,
+			thenRequests: []string{"GET http://customer/Alex", "GET http://orders/7"}},
+		{name: "http client mapper in graphql mode",
+			givenOptions: Options{Mode: HTTPClientMapper, Template: `{"capital": {{ .data.country.capital | quote }}}`,
+				GraphQL:       &cehttpclienttransformer.GraphQL{URL: "http://countries/", Query: "query($code: ID!) { country(code: $code) { capital } }", VariablesTemplate: `{"code": {{ .data.name | quote }}}`, FailOnErrors: true},
+				MockResponses: []string{`{"data": {"country": {"capital": "Berlin"}}}`}},
+			thenData:     `{"capital":"Berlin"}`,
+			thenRequests: []string{`{"method":"POST","url":"http://countries/","headers":{"Accept":"application/json","Content-Type":"application/json"},"json":{"query":"query($code: ID!) { country(code: $code) { capital } }","variables":{"code": "Alex"}}}`}},
+		{name: "graphql mode with steps",
+			givenOptions: Options{Mode: HTTPClientMapper, GraphQL: &cehttpclienttransformer.GraphQL{URL: "http://countries/", Query: "{ countries { code } }"},
+				Steps: []cehttpclienttransformer.Step{{Name: "customer", RequestTemplate: `GET http://customer/{{ .data.name }}`}}},
+			thenError: true},
+		{name: "unknown mode",
+			givenOptions: Options{Mode: "unknown"},
+			thenError:    true},
+		{name: "template error",
+			givenOptions: Options{Mode: Mapper, Template: `{{ notexists }}`},
+			thenError:    true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.givenOptions, inputEvent)
+			if (err != nil) != tt.thenError {
+				t.Fatalf("Render() error = %v, thenError %v", err, tt.thenError)
+			}
+			if err != nil {
+				return
+			}
+			if tt.thenData != "" && string(got.Event.Data()) != tt.thenData {
+				t.Errorf("Render() data = %s, want %s", got.Event.Data(), tt.thenData)
+			}
+			if got.Verdict != tt.thenVerdict {
+				t.Errorf("Render() verdict = %v, want %v", got.Verdict, tt.thenVerdict)
+			}
+			if len(got.Requests) > 0 || len(tt.thenRequests) > 0 {
+				if !reflect.DeepEqual(got.Requests, tt.thenRequests) {
+					t.Errorf("Render() requests = %q, want %q", got.Requests, tt.thenRequests)
+				}
+			}
+		})
+	}
+}
+
+func TestReadEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		given     string
+		thenID    string
+		thenData  string
+		thenError bool
+	}{
+		{name: "structured event",
+			given:    `{"specversion":"1.0","id":"1","source":"test","type":"order","datacontenttype":"application/json","data":{"order":1}}`,
+			thenID:   "1",
+			thenData: `{"order":1}`},
+		{name: "data only",
+			given:    `{"order": 1}`,
+			thenID:   "render",
+			thenData: `{"order":1}`},
+		{name: "invalid json",
+			given:     `{`,
+			thenError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadEvent([]byte(tt.given))
+			if (err != nil) != tt.thenError {
+				t.Fatalf("ReadEvent() error = %v, thenError %v", err, tt.thenError)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID() != tt.thenID || string(got.Data()) != tt.thenData {
+				t.Errorf("ReadEvent() = %v, want id %s and data %s", got, tt.thenID, tt.thenData)
+			}
+		})
+	}
+}
+
+func TestParseMockResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		given           string
+		thenStatus      int
+		thenContentType string
+		thenBody        string
+	}{
+		{name: "raw response",
+			given:           "HTTP/1.1 404 Not Found\nContent-Type: text/plain\n\nnot found",
+			thenStatus:      404,
+			thenContentType: "text/plain",
+			thenBody:        "not found"},
+		{name: "json body",
+			given:           `{"id": 7}`,
+			thenStatus:      200,
+			thenContentType: "application/json",
+			thenBody:        `{"id": 7}`},
+		{name: "text body",
+			given:      "hello",
+			thenStatus: 200,
+			thenBody:   "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMockResponse(tt.given)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := ioutil.ReadAll(got.Body)
+			if got.StatusCode != tt.thenStatus || got.Header.Get("Content-Type") != tt.thenContentType || string(body) != tt.thenBody {
+				t.Errorf("ParseMockResponse() = %d %s %s, want %d %s %s", got.StatusCode, got.Header.Get("Content-Type"), body, tt.thenStatus, tt.thenContentType, tt.thenBody)
+			}
+		})
+	}
+}
+
+func TestValueOrFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("{{ .data }}\n")
+	file.Close()
+	if got, err := ValueOrFile("value", ""); got != "value" || err != nil {
+		t.Errorf("ValueOrFile() without file = '%s', %v", got, err)
+	}
+	if got, err := ValueOrFile("value", file.Name()); got != "{{ .data }}" || err != nil {
+		t.Errorf("ValueOrFile() with file = '%s', %v, want the content without final line break", got, err)
+	}
+}
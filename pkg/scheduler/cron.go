@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the activation times of a scheduler
+type Schedule interface {
+	// Next activation time after t, zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// PeriodSchedule activation with a fixed period
+type PeriodSchedule struct {
+	Period time.Duration
+}
+
+// Next bla
+func (ps PeriodSchedule) Next(t time.Time) time.Time {
+	return t.Add(ps.Period)
+}
+
+// CronSchedule activation times defined by a cron expression
+type CronSchedule struct {
+	second [60]bool
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+	// domStar, dowStar true if day of month or day of week is unrestricted
+	domStar bool
+	dowStar bool
+	loc     *time.Location
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseSchedule parses a cron expression with optional seconds field ("[second] minute hour day-of-month month day-of-week"),
+// a macro like "@hourly" or "@every <duration>". A "CRON_TZ=<zone>" prefix overrides the location loc.
+func ParseSchedule(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		i := strings.Index(expr, " ")
+		if i < 0 {
+			return nil, fmt.Errorf("missing cron expression after time zone: '%s'", expr)
+		}
+		zone := expr[strings.Index(expr, "=")+1 : i]
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid time zone '%s': %v", zone, err)
+		}
+		expr = strings.TrimSpace(expr[i:])
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	if strings.HasPrefix(expr, "@every ") {
+		period, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid period in '%s': %v", expr, err)
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("period must be positive: '%s'", expr)
+		}
+		return PeriodSchedule{Period: period}, nil
+	}
+	if macro, ok := macros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid cron expression '%s', expected 5 or 6 fields", expr)
+	}
+	cs := &CronSchedule{loc: loc}
+	if err := parseField(fields[0], secondField, cs.second[:]); err != nil {
+		return nil, err
+	}
+	if err := parseField(fields[1], minuteField, cs.minute[:]); err != nil {
+		return nil, err
+	}
+	if err := parseField(fields[2], hourField, cs.hour[:]); err != nil {
+		return nil, err
+	}
+	if err := parseField(fields[3], domField, cs.dom[:]); err != nil {
+		return nil, err
+	}
+	if err := parseField(fields[4], monthField, cs.month[:]); err != nil {
+		return nil, err
+	}
+	dow := [8]bool{}
+	if err := parseField(fields[5], dowField, dow[:]); err != nil {
+		return nil, err
+	}
+	copy(cs.dow[:], dow[:7])
+	cs.dow[0] = cs.dow[0] || dow[7]
+	cs.domStar = fields[3] == "*" || fields[3] == "?"
+	cs.dowStar = fields[5] == "*" || fields[5] == "?"
+	return cs, nil
+}
+
+func parseField(expr string, f field, bits []bool) error {
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return fmt.Errorf("invalid step in %s field '%s'", f.name, part)
+			}
+		}
+		from, to := f.min, f.max
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if from, err = f.value(bounds[0]); err != nil {
+				return err
+			}
+			if to, err = f.value(bounds[1]); err != nil {
+				return err
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return err
+			}
+			from = value
+			if step == 1 {
+				to = value
+			}
+		}
+		if from > to {
+			return fmt.Errorf("invalid range in %s field '%s'", f.name, part)
+		}
+		for v := from; v <= to; v += step {
+			bits[v] = true
+		}
+	}
+	return nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s '%s', expected %d-%d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next bla
+func (cs *CronSchedule) Next(t time.Time) time.Time {
+	t = t.In(cs.loc)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !cs.month[t.Month()] {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, cs.loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+	for !cs.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, cs.loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+	for !cs.hour[t.Hour()] {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, cs.loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+	for !cs.minute[t.Minute()] {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, cs.loc)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+	for !cs.second[t.Second()] {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, 0, cs.loc)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+	return t
+}
+
+// dayMatches day of month and day of week are combined with OR, if both are restricted
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cs.dom[t.Day()]
+	dowMatch := cs.dow[t.Weekday()]
+	if cs.domStar || cs.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
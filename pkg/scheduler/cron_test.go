@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	tests := []struct {
+		name        string
+		givenExpr   string
+		whenTime    string
+		thenWant    string
+		thenWantErr bool
+	}{
+		{name: "every minute", givenExpr: "* * * * *", whenTime: "2020-11-01T10:00:30Z", thenWant: "2020-11-01T10:01:00Z"},
+		{name: "with seconds", givenExpr: "*/15 * * * * *", whenTime: "2020-11-01T10:00:30Z", thenWant: "2020-11-01T10:00:45Z"},
+		{name: "hour wrap", givenExpr: "0 30 9 * * *", whenTime: "2020-11-01T10:00:00Z", thenWant: "2020-11-02T09:30:00Z"},
+		{name: "range and list", givenExpr: "0 0 8-10,14 * * *", whenTime: "2020-11-01T10:00:00Z", thenWant: "2020-11-01T14:00:00Z"},
+		{name: "day of week names", givenExpr: "0 0 0 * * MON-FRI", whenTime: "2020-10-31T12:00:00Z", thenWant: "2020-11-02T00:00:00Z"},
+		{name: "sunday as 7", givenExpr: "0 0 * * 7", whenTime: "2020-10-30T12:00:00Z", thenWant: "2020-11-01T00:00:00Z"},
+		{name: "dom or dow", givenExpr: "0 0 0 15 * SUN", whenTime: "2020-11-02T00:00:00Z", thenWant: "2020-11-08T00:00:00Z"},
+		{name: "month and year wrap", givenExpr: "0 0 0 1 JAN *", whenTime: "2020-11-01T00:00:00Z", thenWant: "2021-01-01T00:00:00Z"},
+		{name: "leap day", givenExpr: "0 0 29 2 *", whenTime: "2021-01-01T00:00:00Z", thenWant: "2024-02-29T00:00:00Z"},
+		{name: "macro", givenExpr: "@hourly", whenTime: "2020-11-01T10:00:00Z", thenWant: "2020-11-01T11:00:00Z"},
+		{name: "every", givenExpr: "@every 90s", whenTime: "2020-11-01T10:00:00Z", thenWant: "2020-11-01T10:01:30Z"},
+		{name: "time zone", givenExpr: "CRON_TZ=Europe/Berlin 0 9 * * *", whenTime: "2020-11-01T10:00:00Z", thenWant: "2020-11-02T08:00:00Z"},
+		{name: "never", givenExpr: "0 0 30 2 *", whenTime: "2020-11-01T10:00:00Z", thenWant: "0001-01-01T00:00:00Z"},
+		{name: "too few fields", givenExpr: "* * *", thenWantErr: true},
+		{name: "out of range", givenExpr: "0 24 * * *", thenWantErr: true},
+		{name: "invalid step", givenExpr: "*/0 * * * *", thenWantErr: true},
+		{name: "invalid range", givenExpr: "0 10-8 * * *", thenWantErr: true},
+		{name: "invalid time zone", givenExpr: "CRON_TZ=Mars/Base * * * * *", thenWantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.givenExpr, time.UTC)
+			if (err != nil) != tt.thenWantErr {
+				t.Errorf("ParseSchedule() error = %v, wantErr %v", err, tt.thenWantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			when, _ := time.Parse(time.RFC3339, tt.whenTime)
+			got := schedule.Next(when)
+			if got.UTC().Format(time.RFC3339) != tt.thenWant {
+				t.Errorf("Schedule.Next() = %v, want %v", got.UTC().Format(time.RFC3339), tt.thenWant)
+			}
+		})
+	}
+	schedule, _ := ParseSchedule("0 9 * * *", berlin)
+	if got := schedule.Next(time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)); !got.Equal(time.Date(2020, 11, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Schedule.Next() with location = %v", got)
+	}
+}
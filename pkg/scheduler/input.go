@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alitari/ce-go-template/pkg/transformer"
+	"gopkg.in/yaml.v2"
+)
+
+// InputSource static template data of a producer, which is combined with the tick of each activation
+type InputSource struct {
+	// Hostname name of the host, in k8s the name of the pod
+	Hostname string
+	// PodName value of the env variable POD_NAME, usually set by the downward API
+	PodName string
+	// Env selected environment variables
+	Env map[string]string
+	// Data content of the data file
+	Data interface{}
+}
+
+// NewInputSource collects hostname, pod name, the environment variables with the given names and the content of an optional json or yaml data file
+func NewInputSource(envNames []string, dataFile string) (*InputSource, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]string{}
+	for _, name := range envNames {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+	var data interface{}
+	if dataFile != "" {
+		if data, err = ReadDataFile(dataFile); err != nil {
+			return nil, err
+		}
+	}
+	return &InputSource{Hostname: hostname, PodName: os.Getenv("POD_NAME"), Env: env, Data: data}, nil
+}
+
+// ReadDataFile reads a json or yaml file, yaml is assumed for the extensions .yaml and .yml
+func ReadDataFile(fileName string) (interface{}, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &data); err != nil {
+			return nil, fmt.Errorf("invalid yaml in data file '%s': %v", fileName, err)
+		}
+		return transformer.JSONCompatible(data), nil
+	default:
+		if err := json.Unmarshal(content, &data); err != nil {
+			return nil, fmt.Errorf("invalid json in data file '%s': %v", fileName, err)
+		}
+		return data, nil
+	}
+}
+
+// Input template data of a tick
+func (is *InputSource) Input(tick Tick) map[string]interface{} {
+	return map[string]interface{}{
+		"schedule": tick.Input(),
+		"host":     map[string]interface{}{"name": is.Hostname, "pod": is.PodName},
+		"env":      is.Env,
+		"data":     is.Data,
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadDataFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name          string
+		givenFileName string
+		givenContent  string
+		thenData      interface{}
+		thenError     bool
+	}{
+		{name: "json",
+			givenFileName: "data.json",
+			givenContent:  `{"customers": [{"name": "Alex"}]}`,
+			thenData:      map[string]interface{}{"customers": []interface{}{map[string]interface{}{"name": "Alex"}}}},
+		{name: "yaml",
+			givenFileName: "data.yaml",
+			givenContent:  "customers:\n- name: Alex\n  id: 1\n",
+			thenData:      map[string]interface{}{"customers": []interface{}{map[string]interface{}{"name": "Alex", "id": 1}}}},
+		{name: "invalid json",
+			givenFileName: "invalid.json",
+			givenContent:  `{"customers": `,
+			thenError:     true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(dir, tt.givenFileName)
+			if err := ioutil.WriteFile(fileName, []byte(tt.givenContent), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadDataFile(fileName)
+			if (err != nil) != tt.thenError {
+				t.Fatalf("ReadDataFile() error = %v, thenError %v", err, tt.thenError)
+			}
+			if !reflect.DeepEqual(got, tt.thenData) {
+				t.Errorf("ReadDataFile() = %v, want %v", got, tt.thenData)
+			}
+		})
+	}
+}
+
+func TestNewInputSource(t *testing.T) {
+	os.Setenv("INPUT_TEST_REGION", "eu")
+	os.Setenv("POD_NAME", "producer-0")
+	defer os.Unsetenv("INPUT_TEST_REGION")
+	defer os.Unsetenv("POD_NAME")
+	is, err := NewInputSource([]string{"INPUT_TEST_REGION", "INPUT_TEST_MISSING"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tick := Tick{Time: time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC), Count: 7}
+	input := is.Input(tick)
+	if got := input["env"]; !reflect.DeepEqual(got, map[string]string{"INPUT_TEST_REGION": "eu"}) {
+		t.Errorf("input env = %v", got)
+	}
+	if got := input["host"].(map[string]interface{})["pod"]; got != "producer-0" {
+		t.Errorf("input host.pod = %v, want producer-0", got)
+	}
+	schedule := input["schedule"].(map[string]interface{})
+	if schedule["count"] != uint64(7) || schedule["timestamp"] != "2020-11-01T10:00:00Z" {
+		t.Errorf("input schedule = %v", schedule)
+	}
+}
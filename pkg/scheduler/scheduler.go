@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Config configuration of a scheduler
+type Config struct {
+	Schedule Schedule
+	// Start no activation before start, ignored if zero
+	Start time.Time
+	// End no activation after end, ignored if zero
+	End time.Time
+	// Jitter random delay up to jitter added to every activation
+	Jitter time.Duration
+	// MaxEvents maximum number of activations, unlimited if 0
+	MaxEvents uint64
+	// RunOnStart activate immediately when the scheduler starts
+	RunOnStart bool
+}
+
+// Tick an activation of the scheduler
+type Tick struct {
+	// Time scheduled activation time without jitter
+	Time time.Time
+	// Count number of the activation starting with 1
+	Count uint64
+}
+
+// Input template data of the tick
+func (t Tick) Input() map[string]interface{} {
+	return map[string]interface{}{"time": t.Time, "timestamp": t.Time.Format(time.RFC3339Nano), "unix": t.Time.Unix(), "count": t.Count}
+}
+
+// Scheduler calls a function according to a schedule
+type Scheduler struct {
+	config Config
+	now    func() time.Time
+	after  func(time.Duration) <-chan time.Time
+	random func(int64) int64
+}
+
+// NewScheduler bla
+func NewScheduler(config Config) *Scheduler {
+	return &Scheduler{config: config, now: time.Now, after: time.After, random: rand.Int63n}
+}
+
+// Run calls fire for every activation until the context is done, the end of the schedule is reached or the maximum number of events is fired
+func (s *Scheduler) Run(ctx context.Context, fire func(Tick)) error {
+	var count uint64
+	now := s.now()
+	if s.config.RunOnStart && s.inWindow(now) {
+		count++
+		fire(Tick{Time: now, Count: count})
+	}
+	next := s.config.Schedule.Next(now)
+	if now.Before(s.config.Start) {
+		next = s.first()
+	}
+	for s.config.MaxEvents == 0 || count < s.config.MaxEvents {
+		if next.IsZero() || (!s.config.End.IsZero() && next.After(s.config.End)) {
+			return nil
+		}
+		delay := next.Sub(s.now())
+		if s.config.Jitter > 0 {
+			delay += time.Duration(s.random(int64(s.config.Jitter)))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.after(delay):
+		}
+		count++
+		fire(Tick{Time: next, Count: count})
+		next = s.config.Schedule.Next(next)
+	}
+	return nil
+}
+
+// first activation at or after the start of the window, a fixed period starts with the window
+func (s *Scheduler) first() time.Time {
+	if _, ok := s.config.Schedule.(PeriodSchedule); ok {
+		return s.config.Start
+	}
+	return s.config.Schedule.Next(s.config.Start.Add(-time.Second))
+}
+
+func (s *Scheduler) inWindow(t time.Time) bool {
+	return !t.Before(s.config.Start) && (s.config.End.IsZero() || !t.After(s.config.End))
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func fakeScheduler(config Config, start time.Time) (*Scheduler, *time.Time) {
+	now := start
+	s := NewScheduler(config)
+	s.now = func() time.Time { return now }
+	s.after = func(d time.Duration) <-chan time.Time {
+		now = now.Add(d)
+		c := make(chan time.Time, 1)
+		c <- now
+		return c
+	}
+	s.random = func(n int64) int64 { return n - 1 }
+	return s, &now
+}
+
+func TestScheduler_Run(t *testing.T) {
+	start := time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)
+	minute := PeriodSchedule{Period: time.Minute}
+	everyFiveMinutes, _ := ParseSchedule("*/5 * * * *", time.UTC)
+	tests := []struct {
+		name      string
+		config    Config
+		thenTicks []Tick
+	}{
+		{name: "max events",
+			config:    Config{Schedule: minute, MaxEvents: 2},
+			thenTicks: []Tick{{Time: start.Add(time.Minute), Count: 1}, {Time: start.Add(2 * time.Minute), Count: 2}}},
+		{name: "run on start",
+			config:    Config{Schedule: minute, MaxEvents: 2, RunOnStart: true},
+			thenTicks: []Tick{{Time: start, Count: 1}, {Time: start.Add(time.Minute), Count: 2}}},
+		{name: "window",
+			config:    Config{Schedule: minute, Start: start.Add(10 * time.Minute), End: start.Add(12 * time.Minute), RunOnStart: true},
+			thenTicks: []Tick{{Time: start.Add(10 * time.Minute), Count: 1}, {Time: start.Add(11 * time.Minute), Count: 2}, {Time: start.Add(12 * time.Minute), Count: 3}}},
+		{name: "cron window",
+			config:    Config{Schedule: everyFiveMinutes, Start: start.Add(10 * time.Minute), End: start.Add(12 * time.Minute)},
+			thenTicks: []Tick{{Time: start.Add(10 * time.Minute), Count: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := fakeScheduler(tt.config, start)
+			ticks := []Tick{}
+			if err := s.Run(context.Background(), func(tick Tick) { ticks = append(ticks, tick) }); err != nil {
+				t.Errorf("Scheduler.Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(ticks, tt.thenTicks) {
+				t.Errorf("Scheduler.Run() ticks = %v, want %v", ticks, tt.thenTicks)
+			}
+		})
+	}
+}
+
+func TestScheduler_RunJitter(t *testing.T) {
+	start := time.Date(2020, 11, 1, 10, 0, 0, 0, time.UTC)
+	s, now := fakeScheduler(Config{Schedule: PeriodSchedule{Period: time.Minute}, Jitter: time.Second, MaxEvents: 1}, start)
+	s.Run(context.Background(), func(tick Tick) {
+		if !tick.Time.Equal(start.Add(time.Minute)) {
+			t.Errorf("Tick.Time = %v, must not contain the jitter", tick.Time)
+		}
+		if !now.Equal(start.Add(time.Minute + time.Second - 1)) {
+			t.Errorf("fired at %v, want with jitter", *now)
+		}
+	})
+}
+
+func TestScheduler_RunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := NewScheduler(Config{Schedule: PeriodSchedule{Period: time.Hour}})
+	if err := s.Run(ctx, func(tick Tick) { t.Errorf("unexpected tick %v", tick) }); err != context.Canceled {
+		t.Errorf("Scheduler.Run() error = %v, want %v", err, context.Canceled)
+	}
+}
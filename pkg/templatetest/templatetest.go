@@ -0,0 +1,261 @@
+package templatetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alitari/ce-go-template/pkg/cehttpclienttransformer"
+	"github.com/alitari/ce-go-template/pkg/cetransformer"
+	"github.com/alitari/ce-go-template/pkg/render"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ConfigFile marks a template directory
+	ConfigFile = "template.yaml"
+	// CasesDir directory of the test cases in a template directory
+	CasesDir = "testcases"
+	// EventFile input event of a test case
+	EventFile = "event.json"
+	// ExpectedEventFile expected output event of a mapper test case
+	ExpectedEventFile = "expected.json"
+	// ExpectedVerdictFile expected verdict of a filter test case
+	ExpectedVerdictFile = "expected.txt"
+	// ResponseFilePattern mocked http responses of a test case, used in lexical order
+	ResponseFilePattern = "response*"
+)
+
+// TemplateConfig content of the config file of a template directory. File names are relative to the template directory.
+type TemplateConfig struct {
+	Mode                string `yaml:"mode"`
+	Template            string `yaml:"template"`
+	TemplateFile        string `yaml:"templateFile"`
+	Source              string `yaml:"source"`
+	Type                string `yaml:"type"`
+	RequestTemplate     string `yaml:"requestTemplate"`
+	RequestTemplateFile string `yaml:"requestTemplateFile"`
+	StepsFile           string `yaml:"stepsFile"`
+	JSONBody            *bool  `yaml:"jsonBody"`
+	// GraphQL mode like GRAPHQL_* of the http modes
+	GraphQLURL               string            `yaml:"graphqlUrl"`
+	GraphQLQueryFile         string            `yaml:"graphqlQueryFile"`
+	GraphQLVariablesTemplate string            `yaml:"graphqlVariablesTemplate"`
+	GraphQLHeaders           map[string]string `yaml:"graphqlHeaders"`
+	GraphQLFailOnErrors      *bool             `yaml:"graphqlFailOnErrors"`
+}
+
+// CaseResult result of a test case, passed if there are no diffs and no error
+type CaseResult struct {
+	Template string
+	Case     string
+	Diffs    []string
+	Err      error
+	// Updated the expected file was written
+	Updated bool
+}
+
+// Passed bla
+func (cr CaseResult) Passed() bool {
+	return cr.Err == nil && len(cr.Diffs) == 0
+}
+
+// Run runs the test cases of all template directories below root. With update the expected files are written instead of compared.
+func Run(root string, update bool) ([]CaseResult, error) {
+	templateDirs := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.Name() == ConfigFile {
+			templateDirs = append(templateDirs, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(templateDirs) == 0 {
+		return nil, fmt.Errorf("no '%s' found below '%s'", ConfigFile, root)
+	}
+	results := []CaseResult{}
+	for _, dir := range templateDirs {
+		name, err := filepath.Rel(root, dir)
+		if err != nil {
+			name = dir
+		}
+		options, err := readOptions(dir)
+		if err != nil {
+			results = append(results, CaseResult{Template: name, Err: err})
+			continue
+		}
+		cases, err := ioutil.ReadDir(filepath.Join(dir, CasesDir))
+		if err != nil {
+			results = append(results, CaseResult{Template: name, Err: err})
+			continue
+		}
+		for _, c := range cases {
+			if !c.IsDir() {
+				continue
+			}
+			result := runCase(options, filepath.Join(dir, CasesDir, c.Name()), update)
+			result.Template, result.Case = name, c.Name()
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func readOptions(dir string) (render.Options, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		return render.Options{}, err
+	}
+	config := TemplateConfig{}
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return render.Options{}, fmt.Errorf("invalid %s: %v", ConfigFile, err)
+	}
+	if config.Mode == "" {
+		config.Mode = string(render.Mapper)
+	}
+	options := render.Options{Mode: render.Mode(config.Mode), Source: config.Source, Type: config.Type, JSONBody: config.JSONBody == nil || *config.JSONBody}
+	if options.Template, err = render.ValueOrFile(config.Template, relative(dir, config.TemplateFile)); err != nil {
+		return options, err
+	}
+	if options.RequestTemplate, err = render.ValueOrFile(config.RequestTemplate, relative(dir, config.RequestTemplateFile)); err != nil {
+		return options, err
+	}
+	if config.GraphQLURL != "" {
+		failOnErrors := config.GraphQLFailOnErrors == nil || *config.GraphQLFailOnErrors
+		if options.GraphQL, err = render.NewGraphQL(config.GraphQLURL, relative(dir, config.GraphQLQueryFile), config.GraphQLVariablesTemplate, config.GraphQLHeaders, failOnErrors); err != nil {
+			return options, err
+		}
+	}
+	if config.StepsFile != "" {
+		steps, err := ioutil.ReadFile(filepath.Join(dir, config.StepsFile))
+		if err != nil {
+			return options, err
+		}
+		if options.Steps, err = cehttpclienttransformer.ParseSteps(string(steps)); err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// relative the file name relative to the template directory, empty if not set
+func relative(dir, fileName string) string {
+	if fileName == "" {
+		return ""
+	}
+	return filepath.Join(dir, fileName)
+}
+
+func runCase(options render.Options, dir string, update bool) CaseResult {
+	content, err := ioutil.ReadFile(filepath.Join(dir, EventFile))
+	if err != nil {
+		return CaseResult{Err: err}
+	}
+	event, err := render.ReadEvent(content)
+	if err != nil {
+		return CaseResult{Err: err}
+	}
+	responseFiles, err := filepath.Glob(filepath.Join(dir, ResponseFilePattern))
+	if err != nil {
+		return CaseResult{Err: err}
+	}
+	sort.Strings(responseFiles)
+	options.MockResponses = nil
+	for _, fileName := range responseFiles {
+		response, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return CaseResult{Err: err}
+		}
+		options.MockResponses = append(options.MockResponses, string(response))
+	}
+	result, err := render.Render(options, event)
+	if err != nil {
+		return CaseResult{Err: err}
+	}
+	isFilter := options.Mode == render.Filter || options.Mode == render.HTTPClientFilter
+	if update {
+		return writeExpected(dir, isFilter, result)
+	}
+	if isFilter {
+		return CaseResult{Diffs: diffVerdict(dir, result.Verdict)}
+	}
+	return CaseResult{Diffs: diffEvent(dir, *result.Event)}
+}
+
+func diffVerdict(dir string, verdict bool) []string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ExpectedVerdictFile))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	want := strings.TrimSpace(string(content))
+	if got := fmt.Sprint(verdict); got != want {
+		return []string{fmt.Sprintf("verdict not fit. actual= '%s', want '%s'", got, want)}
+	}
+	return nil
+}
+
+// diffEvent compares the data and, if present in the expected file, the source and type
+func diffEvent(dir string, actual cloudevents.Event) []string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ExpectedEventFile))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	expected, err := render.ReadEvent(content)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	// normalize the data to the compact json produced by the transformer
+	data := map[string]interface{}{}
+	if err := expected.DataAs(&data); err != nil {
+		return []string{err.Error()}
+	}
+	if err := expected.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return []string{err.Error()}
+	}
+	if !hasAttributes(content) {
+		expected.SetSource(actual.Source())
+		expected.SetType(actual.Type())
+	}
+	return cetransformer.DiffEvents(actual, expected)
+}
+
+func hasAttributes(content []byte) bool {
+	probe := map[string]interface{}{}
+	json.Unmarshal(content, &probe)
+	_, ok := probe["specversion"]
+	return ok
+}
+
+func writeExpected(dir string, isFilter bool, result *render.Result) CaseResult {
+	if isFilter {
+		err := ioutil.WriteFile(filepath.Join(dir, ExpectedVerdictFile), []byte(fmt.Sprintln(result.Verdict)), 0644)
+		return CaseResult{Err: err, Updated: err == nil}
+	}
+	expected := map[string]interface{}{
+		"specversion":     result.Event.SpecVersion(),
+		"source":          result.Event.Source(),
+		"type":            result.Event.Type(),
+		"datacontenttype": result.Event.DataContentType(),
+	}
+	data := map[string]interface{}{}
+	if err := result.Event.DataAs(&data); err != nil {
+		return CaseResult{Err: err}
+	}
+	expected["data"] = data
+	content, err := json.MarshalIndent(expected, "", "  ")
+	if err != nil {
+		return CaseResult{Err: err}
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, ExpectedEventFile), append(content, '\n'), 0644)
+	return CaseResult{Err: err, Updated: err == nil}
+}
@@ -0,0 +1,89 @@
+package templatetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	results, err := Run("testdata", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		thenPassed bool
+		thenDiff   string
+	}{
+		{name: "country/de", thenPassed: true},
+		{name: "customer/found", thenPassed: true},
+		{name: "greeting/alex", thenPassed: true},
+		{name: "greeting/wrong", thenPassed: false, thenDiff: `payload not equal. actual = '{"greeting":"Hello Dani"}', want '{"greeting":"Hello Alex"}'`},
+		{name: "vip-filter/vip", thenPassed: true},
+	}
+	if len(results) != len(tests) {
+		t.Fatalf("Run() got %d results, want %d: %v", len(results), len(tests), results)
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := results[i]
+			if got.Template+"/"+got.Case != tt.name {
+				t.Fatalf("Run() result %d is %s/%s, want %s", i, got.Template, got.Case, tt.name)
+			}
+			if got.Passed() != tt.thenPassed {
+				t.Errorf("Run() passed = %v, want %v: %v %v", got.Passed(), tt.thenPassed, got.Err, got.Diffs)
+			}
+			if tt.thenDiff != "" && (len(got.Diffs) != 1 || got.Diffs[0] != tt.thenDiff) {
+				t.Errorf("Run() diffs = %q, want %q", got.Diffs, tt.thenDiff)
+			}
+		})
+	}
+}
+
+func TestRun_update(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templatetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caseDir := filepath.Join(dir, CasesDir, "alex")
+	os.MkdirAll(caseDir, 0755)
+	ioutil.WriteFile(filepath.Join(dir, ConfigFile), []byte(`template: '{ "name": {{ .data.name | upper | quote }} }'`), 0644)
+	ioutil.WriteFile(filepath.Join(caseDir, EventFile), []byte(`{"name": "Alex"}`), 0644)
+
+	results, err := Run(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Updated {
+		t.Fatalf("Run() with update = %v, want one updated result", results)
+	}
+	expected, err := ioutil.ReadFile(filepath.Join(caseDir, ExpectedEventFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(expected), `"name": "ALEX"`) {
+		t.Errorf("expected file = %s, want name ALEX", expected)
+	}
+	results, err = Run(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Passed() {
+		t.Errorf("Run() after update must pass, but %v %v", results[0].Err, results[0].Diffs)
+	}
+}
+
+func TestRun_noTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templatetest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := Run(dir, false); err == nil {
+		t.Errorf("Run() without templates must fail")
+	}
+}
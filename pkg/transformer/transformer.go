@@ -2,10 +2,12 @@ package transformer
 
 import (
 	"bytes"
-	"log"
+	"encoding/json"
+	"sync/atomic"
 	"text/template"
 
 	sprig "github.com/Masterminds/sprig"
+	"github.com/alitari/ce-go-template/pkg/logging"
 )
 
 // Config Coinfiguration for the transformer
@@ -25,7 +27,7 @@ func NewTransformer(ceTemplate string, funcMapExtension template.FuncMap, debug
 	if funcMapExtension == nil {
 		funcMapExtension = template.FuncMap{
 			"count": func() uint64 {
-				return t.count
+				return atomic.LoadUint64(&t.count)
 			},
 		}
 	}
@@ -41,14 +43,17 @@ func NewTransformer(ceTemplate string, funcMapExtension template.FuncMap, debug
 
 // TransformInputToBytes bla
 func (ct *Transformer) TransformInputToBytes(input interface{}) ([]byte, error) {
-	ct.count++
+	atomic.AddUint64(&ct.count, 1)
 	buf := &bytes.Buffer{}
 	err := ct.tplt.Execute(buf, input)
 	if err != nil {
 		return nil, err
 	}
 	if ct.debug {
-		log.Printf("transformed input data: %v\nto String: '%s'", input, buf.String())
+		if inputJSON, err := json.Marshal(input); err == nil {
+			logging.DebugPayload("template input", inputJSON)
+		}
+		logging.DebugPayload("template output", buf.Bytes())
 	}
 	return buf.Bytes(), nil
 }
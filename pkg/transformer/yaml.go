@@ -0,0 +1,22 @@
+package transformer
+
+import "fmt"
+
+// JSONCompatible converts the map[interface{}]interface{} structures of yaml to map[string]interface{}, so that templates and json see the same data
+func JSONCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, e := range v {
+			m[fmt.Sprint(k)] = JSONCompatible(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = JSONCompatible(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
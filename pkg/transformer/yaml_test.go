@@ -0,0 +1,14 @@
+package transformer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONCompatible(t *testing.T) {
+	given := map[interface{}]interface{}{"name": "Alex", 1: []interface{}{map[interface{}]interface{}{"nested": true}}}
+	want := map[string]interface{}{"name": "Alex", "1": []interface{}{map[string]interface{}{"nested": true}}}
+	if got := JSONCompatible(given); !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONCompatible() = %v, want %v", got, want)
+	}
+}